@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	adoptMove     bool
+	adoptTemplate string
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path>",
+	Short: "Adopt an existing directory as a playground",
+	Long: `Turn an existing directory into a playground. The name and module path are
+taken from go.mod, the template is guessed from the imports and the creation
+time comes from the earliest git commit or file.
+
+By default the directory stays where it is and is registered by reference;
+removing the playground never deletes it. Use --move to move it into the
+workspace instead.
+Example: goshed adopt ~/experiments/ratelimit --tags concurrency`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := project.InspectDir(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to inspect directory: %w", err)
+		}
+		if projectName != "" {
+			p.Name = projectName
+		}
+		if adoptTemplate != "" {
+			p.Template = adoptTemplate
+		}
+		if len(tags) > 0 {
+			p.Tags = tags
+		}
+
+		adopter, err := storeFeature[project.Adopter]("adopting directories")
+		if err != nil {
+			return err
+		}
+		p, err = adopter.Adopt(args[0], p, adoptMove)
+		if err != nil {
+			return fmt.Errorf("failed to adopt project: %w", err)
+		}
+
+		how := "by reference"
+		if adoptMove {
+			how = "into the workspace"
+		}
+		fmt.Printf("%s %s %s (template %s)\n",
+			styles.Success("Adopted"),
+			styles.ProjectName(p.Name),
+			how,
+			p.Template,
+		)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+	adoptCmd.Flags().BoolVar(&adoptMove, "move", false, "Move the directory into the workspace instead of referencing it")
+	adoptCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (default: from go.mod)")
+	adoptCmd.Flags().StringVarP(&adoptTemplate, "template", "t", "", "Template to record (default: guessed from imports)")
+	adoptCmd.Flags().StringSliceVarP(&tags, "tags", "", nil, "Tags to categorize the playground")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive a playground",
+	Long: `Pack a playground, including its metadata and Git history, into a
+compressed archive under ~/.goshed/archive and remove it from the active list.
+Archived playgrounds are shown by 'goshed list --archived'. --filter archives
+every playground matching a query instead (see 'goshed list --help'),
+skipping pinned ones unless --force is given.
+Example: goshed archive -n myproject`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archiver, err := storeFeature[project.Archiver]("archiving")
+		if err != nil {
+			return err
+		}
+		if filterExpr != "" {
+			// Keep clean and reindex out while archiving many projects
+			unlock, err := lockWorkspace()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+		}
+		projects, err := selectProjects(projectName)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range projects {
+			if err := checkPinned(p, forceRemove); err != nil {
+				if len(projects) == 1 {
+					return err
+				}
+				fmt.Printf("Skipped %s: pinned\n", styles.ProjectName(p.Name))
+				continue
+			}
+
+			if err := archiver.Archive(p.Name); err != nil {
+				return fmt.Errorf("failed to archive project: %w", err)
+			}
+			fmt.Printf("%s %s\n", styles.Success("Archived"), styles.ProjectName(p.Name))
+		}
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore an archived playground",
+	Long: `Unpack an archived playground back into the workspace exactly as it was.
+Example: goshed restore -n myproject`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectName == "" {
+			return fmt.Errorf("project name is required")
+		}
+
+		archiver, err := storeFeature[project.Archiver]("archiving")
+		if err != nil {
+			return err
+		}
+		p, err := archiver.Unarchive(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to restore project: %w", err)
+		}
+
+		fmt.Printf("%s %s\n", styles.Success("Restored"), styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+// completeArchivedNames completes a --name flag with the archived projects
+func completeArchivedNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := openStore(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	archiver, ok := store.(project.Archiver)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	archived, err := archiver.ListArchived()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(archived))
+	for _, a := range archived {
+		names = append(names, a.Project.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to archive")
+	archiveCmd.Flags().StringVar(&filterExpr, "filter", "", "Archive every playground matching this query instead")
+	archiveCmd.Flags().BoolVar(&forceRemove, "force", false, "Archive the playground even if it is pinned")
+	archiveCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the archived playground to restore (required)")
+	restoreCmd.MarkFlagRequired("name")
+	restoreCmd.RegisterFlagCompletionFunc("name", completeArchivedNames)
+}
@@ -4,52 +4,143 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/crazywolf132/goshed/internal/model"
 	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	olderThan string
+	olderThan    string
+	cleanArchive bool
+	cleanSignal  string
 )
 
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Clean up old playgrounds",
-	Long: `Remove playgrounds that haven't been accessed for a specified duration.
-Example: goshed clean --older-than 720h`,
+	Long: `Remove playgrounds that have shown no activity for a specified duration.
+Activity is judged by --signal: 'accessed' (goshed commands), 'modified'
+(newest file, ignoring .git), 'commit' (newest git commit) or 'any' (the
+latest of all three, the default).
+Removed playgrounds go to the trash and can be brought back with
+'goshed trash restore'. Trash entries older than trash.retention are then
+deleted for good. With --archive, old playgrounds are archived instead of deleted.
+Playgrounds past their own expiry (see 'goshed expire') are removed however
+recently they were accessed. Pinned playgrounds are always kept.
+--filter limits cleanup to the playgrounds matching a query (see 'goshed list
+--help'); --explain shows which those are without cleaning anything.
+Example: goshed clean --older-than 720h --archive --filter 'tag:spike'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		duration, err := time.ParseDuration(olderThan)
+		// The configured default is only known once config has loaded
+		if olderThan == "" {
+			olderThan = viper.GetString("cleanup.older_than")
+		}
+		duration, err := project.ParseDuration(olderThan)
+		if err != nil {
+			return err
+		}
+
+		if cleanSignal == "" {
+			cleanSignal = viper.GetString("cleanup.signal")
+		}
+		if err := project.ValidateSignal(cleanSignal); err != nil {
+			return err
+		}
+
+		query, err := parseFilter()
+		if err != nil {
+			return err
+		}
+
+		var archiver project.Archiver
+		if cleanArchive {
+			if archiver, err = storeFeature[project.Archiver]("archiving"); err != nil {
+				return err
+			}
+		}
+
+		unlock, err := lockWorkspace()
 		if err != nil {
-			return fmt.Errorf("invalid duration format: %w", err)
+			return err
 		}
+		defer unlock()
 
 		// Get all projects
-		projects, err := project.List()
+		projects, err := store.List()
 		if err != nil {
 			return fmt.Errorf("failed to list projects: %w", err)
 		}
 
-		cutoff := time.Now().Add(-duration)
+		if explainExpr {
+			explainFilter(query, projects)
+			return nil
+		}
+		projects = applyFilter(query, projects, true)
+
+		// Bring the file and commit activity up to date before judging it,
+		// unless the filter already did
+		if query == nil || !query.NeedsActivity() {
+			refreshActivities(projects, true)
+		}
+
+		now := time.Now()
+		cutoff := now.Add(-duration)
 		cleaned := 0
 
 		for _, p := range projects {
-			if p.LastAccessed.Before(cutoff) {
-				if err := project.Remove(p.Name); err != nil {
-					fmt.Printf("Warning: failed to remove %s: %v\n", p.Name, err)
+			if p.Pinned {
+				continue
+			}
+
+			var why, reason string
+			switch {
+			case project.Expired(p, now):
+				why = fmt.Sprintf("expired: %s", p.ExpiresAt.Format(time.RFC3339))
+				reason = fmt.Sprintf("clean: expired at %s", p.ExpiresAt.Format(time.RFC3339))
+			case lastActivity(p).Before(cutoff):
+				last := lastActivity(p).Format(time.RFC3339)
+				why = fmt.Sprintf("last %s: %s", cleanSignal, last)
+				reason = fmt.Sprintf("clean: no %s activity since %s", cleanSignal, last)
+			default:
+				continue
+			}
+
+			if cleanArchive {
+				if err := archiver.Archive(p.Name); err != nil {
+					fmt.Printf("Warning: failed to archive %s: %v\n", p.Name, err)
 					continue
 				}
 				cleaned++
-				fmt.Printf("Removed %s (last accessed: %s)\n", p.Name, p.LastAccessed.Format(time.RFC3339))
+				fmt.Printf("Archived %s (%s)\n", p.Name, why)
+				continue
+			}
+
+			if err := store.Remove(p.Name, reason); err != nil {
+				fmt.Printf("Warning: failed to remove %s: %v\n", p.Name, err)
+				continue
 			}
+			cleaned++
+			fmt.Printf("Moved %s to trash (%s)\n", p.Name, why)
 		}
 
 		fmt.Printf("Cleaned up %d projects\n", cleaned)
-		return nil
+
+		return purgeExpiredTrash()
 	},
 }
 
+// lastActivity returns a project's last activity by the chosen signal
+func lastActivity(p *model.Project) time.Time {
+	t, _ := project.LastActivity(p, cleanSignal)
+	return t
+}
+
 func init() {
 	rootCmd.AddCommand(cleanCmd)
-	cleanCmd.Flags().StringVar(&olderThan, "older-than", viper.GetString("cleanup.older_than"), "Remove projects older than this duration (e.g., 720h)")
+	cleanCmd.Flags().StringVar(&olderThan, "older-than", "", "Remove projects older than this duration (e.g., 720h, 30d; defaults to cleanup.older_than)")
+	cleanCmd.Flags().StringVar(&cleanSignal, "signal", "", "Activity to judge age by: accessed, modified, commit or any (defaults to cleanup.signal)")
+	cleanCmd.Flags().StringVar(&filterExpr, "filter", "", filterHelp)
+	cleanCmd.Flags().BoolVar(&explainExpr, "explain", false, "Show how the --filter query is evaluated instead of cleaning")
+	cleanCmd.Flags().BoolVar(&cleanArchive, "archive", false, "Archive old projects instead of deleting them")
 }
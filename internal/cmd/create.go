@@ -8,46 +8,101 @@ import (
 	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/crazywolf132/goshed/internal/styles"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
 	projectName  string
 	templateName string
 	tags         []string
+	createTTL    string
+	createModule string
+	createGo     string
+	createChain  string
+	createSuffix bool
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new Go playground",
 	Long: `Create a new Go playground with the specified name and template.
-Example: goshed create -n myproject -t basic`,
+Names use lowercase letters, digits, '.', '_' and '-'. Without --name a
+memorable name such as brave-otter is generated; with --suffix a name that
+is taken is numbered (myproject-2) instead of failing.
+The module path defaults to the name, under module_prefix if one is
+configured (e.g. module_prefix: github.com/ourorg/sandbox/).
+Example: goshed create -n myproject -t basic --go 1.22 --toolchain go1.23.4`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := newProjectName(projectName, createSuffix)
+		if err != nil {
+			return err
+		}
+
 		p := &model.Project{
-			Name:         projectName,
+			Name:         name,
 			Created:      time.Now(),
 			LastAccessed: time.Now(),
 			Template:     templateName,
+			Module:       createModule,
+			GoVersion:    createGo,
+			Toolchain:    createChain,
 			Tags:         tags,
 		}
+		if p.Module == "" {
+			p.Module = project.DefaultModulePath(viper.GetString("module_prefix"), p.Name)
+		}
+		if createTTL != "" {
+			ttl, err := project.ParseDuration(createTTL)
+			if err != nil {
+				return err
+			}
+			expiresAt := p.Created.Add(ttl)
+			p.ExpiresAt = &expiresAt
+		}
 
-		if err := project.Create(p); err != nil {
+		if err := store.Create(p); err != nil {
 			return fmt.Errorf("%s: %w", styles.Error("%s", "Failed to create project"), err)
 		}
 
 		fmt.Printf("%s %s\n",
 			styles.Success("%s", "Created new playground:"),
-			styles.ProjectName("%s", projectName),
+			styles.ProjectName("%s", p.Name),
 		)
 		return nil
 	},
 }
 
+// newProjectName validates the name given for a new project, numbering it
+// if it is taken and suffix is set, or generates one if it is empty
+func newProjectName(name string, suffix bool) (string, error) {
+	exists := func(name string) bool {
+		return project.NameTaken(store, name)
+	}
+
+	if name == "" {
+		return project.GenerateName(exists), nil
+	}
+	if err := project.ValidateName(name); err != nil {
+		return "", err
+	}
+	if !exists(name) {
+		return name, nil
+	}
+	if !suffix {
+		return "", fmt.Errorf("project %s already exists; use --suffix to create %s instead", name, project.UniqueName(name, exists))
+	}
+	return project.UniqueName(name, exists), nil
+}
+
 func init() {
 	rootCmd.AddCommand(createCmd)
 
-	createCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+	createCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (generated if omitted)")
+	createCmd.Flags().BoolVar(&createSuffix, "suffix", false, "Number the name (name-2, name-3, ...) if it is taken")
 	createCmd.Flags().StringVarP(&templateName, "template", "t", "basic", "Template to use (basic, web, cli)")
 	createCmd.Flags().StringSliceVarP(&tags, "tags", "", nil, "Tags to categorize the playground")
-
-	createCmd.MarkFlagRequired("name")
+	createCmd.Flags().StringVar(&createModule, "module", "", "Module path (defaults to the name under module_prefix)")
+	createCmd.Flags().StringVar(&createGo, "go", "", "Go version for the go directive (default "+project.DefaultGoVersion+")")
+	createCmd.Flags().StringVar(&createChain, "toolchain", "", "Toolchain directive, e.g. go1.23.4")
+	createCmd.Flags().StringVar(&createTTL, "ttl", "", "Let clean remove the playground after this long (e.g., 48h, 7d)")
 }
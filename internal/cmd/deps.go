@@ -5,7 +5,6 @@ import (
 	"os/exec"
 	"strings"
 
-	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/crazywolf132/goshed/internal/styles"
 	"github.com/spf13/cobra"
 )
@@ -20,7 +19,7 @@ Example: goshed deps -n myproject`,
 			return fmt.Errorf("project name is required")
 		}
 
-		p, err := project.Get(projectName)
+		p, err := store.Get(projectName)
 		if err != nil {
 			return fmt.Errorf("failed to get project: %w", err)
 		}
@@ -65,4 +64,5 @@ func init() {
 	rootCmd.AddCommand(depsCmd)
 	depsCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
 	depsCmd.MarkFlagRequired("name")
+	depsCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
 }
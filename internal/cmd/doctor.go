@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check playgrounds and the environment for problems",
+	Long: `Check that go, git and your editor are on PATH, and look for broken
+playground state: orphan directories, corrupt or missing metadata, names
+that don't match their directory, missing or mismatched go.mod files, broken
+.git directories and stale locks.
+
+With --fix, metadata is regenerated, broken directories are moved to the
+workspace quarantine (.goshed/quarantine) and broken git repositories are
+re-initialized.
+Example: goshed doctor --fix`,
+	// A failed check is not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		problems := checkEnvironment()
+
+		d, ok := store.(project.Doctor)
+		if !ok {
+			fmt.Println(styles.Warning("This store cannot be checked for broken playgrounds"))
+			return doctorResult(problems)
+		}
+
+		issues, err := d.Diagnose()
+		if err != nil {
+			return fmt.Errorf("failed to check playgrounds: %w", err)
+		}
+
+		fmt.Printf("\n%s\n", styles.Title("Playgrounds"))
+		if len(issues) == 0 {
+			fmt.Printf("  %s no problems found\n", styles.Success("✓"))
+			return doctorResult(problems)
+		}
+
+		fixed := 0
+		for _, issue := range issues {
+			where := "workspace"
+			if issue.Project != "" {
+				where = styles.ProjectName(issue.Project)
+			}
+			fmt.Printf("  %s %s: %s\n", styles.Error("✗"), where, issue.Problem)
+
+			switch {
+			case issue.Fix == "":
+				fmt.Printf("      needs fixing by hand\n")
+				problems++
+			case !doctorFix:
+				fmt.Printf("      --fix will %s\n", issue.Fix)
+				problems++
+			default:
+				if err := issue.Repair(); err != nil {
+					fmt.Printf("      %s %v\n", styles.Error("fix failed:"), err)
+					problems++
+					continue
+				}
+				fmt.Printf("      %s %s\n", styles.Success("fixed:"), issue.Fix)
+				fixed++
+			}
+		}
+
+		if fixed > 0 {
+			if ix, ok := store.(project.Indexer); ok {
+				if err := ix.Reindex(); err != nil {
+					fmt.Printf("%s %v\n", styles.Warning("Warning: failed to rebuild the index:"), err)
+				}
+			}
+		}
+
+		return doctorResult(problems)
+	},
+}
+
+// checkEnvironment reports whether the tools goshed runs are on PATH and
+// returns the number of problems found
+func checkEnvironment() int {
+	fmt.Println(styles.Title("Environment"))
+
+	tools := []struct {
+		name, command string
+	}{
+		{"go", "go"},
+		{"git", "git"},
+		{"editor", viper.GetString("editor")},
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		tools = append(tools, struct{ name, command string }{"$EDITOR", editor})
+	}
+
+	problems := 0
+	for _, tool := range tools {
+		fields := strings.Fields(tool.command)
+		if len(fields) == 0 {
+			fmt.Printf("  %s %s: not configured\n", styles.Error("✗"), tool.name)
+			problems++
+			continue
+		}
+
+		path, err := exec.LookPath(fields[0])
+		if err != nil {
+			fmt.Printf("  %s %s: %s not found on PATH\n", styles.Error("✗"), tool.name, fields[0])
+			problems++
+			continue
+		}
+		fmt.Printf("  %s %s: %s\n", styles.Success("✓"), tool.name, path)
+	}
+	return problems
+}
+
+func doctorResult(problems int) error {
+	if problems > 0 {
+		return fmt.Errorf("found %d unresolved problems", problems)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the problems that can be fixed automatically")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show disk usage of playgrounds",
+	Long: `Measure how much disk space each playground uses, split into Go source,
+.git, build outputs (binaries, bin/, dist/, build/) and other files, largest
+first, followed by the total for the workspace.
+Example: goshed du`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projects, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+		if projectName != "" {
+			p, err := store.Get(projectName)
+			if err != nil {
+				return fmt.Errorf("failed to get project: %w", err)
+			}
+			projects = []*model.Project{p}
+		}
+		if len(projects) == 0 {
+			fmt.Println(styles.Warning("No playgrounds found"))
+			return nil
+		}
+
+		usages := project.MeasureDiskUsages(projects)
+		now := time.Now()
+
+		type row struct {
+			p *model.Project
+			u project.DiskUsage
+		}
+		rows := make([]row, 0, len(projects))
+		var total project.DiskUsage
+		for i, p := range projects {
+			if usages[i] == nil {
+				fmt.Printf("Warning: failed to measure %s\n", p.Name)
+				continue
+			}
+			rows = append(rows, row{p, *usages[i]})
+			total.Add(*usages[i])
+
+			// Keep the cached size used by 'list --sort=size' fresh
+			project.SetSize(p, *usages[i], now)
+			if err := store.Update(p); err != nil {
+				fmt.Printf("Warning: failed to save size of %s: %v\n", p.Name, err)
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].u.Total() > rows[j].u.Total()
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "TOTAL\tSOURCE\tGIT\tBUILD\tOTHER\t\tNAME")
+		printRow := func(name string, u project.DiskUsage) {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\t%s\n",
+				project.FormatSize(u.Total()),
+				project.FormatSize(u.Source),
+				project.FormatSize(u.Git),
+				project.FormatSize(u.Build),
+				project.FormatSize(u.Other),
+				name,
+			)
+		}
+		for _, r := range rows {
+			printRow(r.p.Name, r.u)
+		}
+		if projectName == "" {
+			fmt.Fprintln(w, "\t\t\t\t\t\t")
+			printRow(fmt.Sprintf("workspace (%d playgrounds)", len(rows)), total)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+	duCmd.Flags().StringVarP(&projectName, "name", "n", "", "Only measure this playground")
+	duCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expireIn    string
+	expireNever bool
+)
+
+var expireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Set when a playground expires",
+	Long: `Set how long a playground should live. Once it expires, 'goshed clean'
+removes it however recently it was accessed. Use --never to clear the expiry.
+Example: goshed expire -n myproject --in 7d`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectName == "" {
+			return fmt.Errorf("project name is required")
+		}
+		if (expireIn == "") == !expireNever {
+			return fmt.Errorf("exactly one of --in or --never is required")
+		}
+
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		if expireNever {
+			p.ExpiresAt = nil
+		} else {
+			d, err := project.ParseDuration(expireIn)
+			if err != nil {
+				return err
+			}
+			expiresAt := time.Now().Add(d)
+			p.ExpiresAt = &expiresAt
+		}
+
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+
+		if p.ExpiresAt == nil {
+			fmt.Printf("%s %s no longer expires\n", styles.Success("Updated"), styles.ProjectName(p.Name))
+			return nil
+		}
+		fmt.Printf("%s %s expires in %s (%s)\n",
+			styles.Success("Updated"),
+			styles.ProjectName(p.Name),
+			project.FormatDuration(time.Until(*p.ExpiresAt)),
+			styles.TimeText(p.ExpiresAt.Format(time.RFC3339)),
+		)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(expireCmd)
+	expireCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+	expireCmd.Flags().StringVar(&expireIn, "in", "", "Expire the playground after this long (e.g., 48h, 7d)")
+	expireCmd.Flags().BoolVar(&expireNever, "never", false, "Remove the playground's expiry")
+	expireCmd.MarkFlagRequired("name")
+	expireCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+}
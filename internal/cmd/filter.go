@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/filter"
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+)
+
+var (
+	filterExpr  string
+	explainExpr bool
+)
+
+// filterHelp describes the --filter flag shared by the commands that
+// select playgrounds with a query
+const filterHelp = `Only include playgrounds matching this query, e.g. 'tag:web and accessed>30d and not pinned'`
+
+// parseFilter parses the --filter query, or returns nil if none was given
+func parseFilter() (*filter.Query, error) {
+	if filterExpr == "" {
+		if explainExpr {
+			return nil, fmt.Errorf("--explain needs a --filter query to explain")
+		}
+		return nil, nil
+	}
+	return filter.Parse(filterExpr)
+}
+
+// applyFilter brings the activity and sizes the query looks at up to date
+// and returns the matching projects. The refreshed values are only saved
+// when save is set. A nil query matches everything.
+func applyFilter(q *filter.Query, projects []*model.Project, save bool) []*model.Project {
+	if q == nil {
+		return projects
+	}
+	if q.NeedsActivity() {
+		refreshActivities(projects, save)
+	}
+	if q.NeedsSize() {
+		refreshSizes(projects, save)
+	}
+	return q.Filter(projects, time.Now())
+}
+
+// refreshActivities brings the file and commit activity of the projects up
+// to date, saving the ones that changed when save is set
+func refreshActivities(projects []*model.Project, save bool) {
+	for _, p := range project.RefreshActivities(projects) {
+		if !save {
+			continue
+		}
+		if err := store.Update(p); err != nil {
+			fmt.Printf("Warning: failed to save activity of %s: %v\n", p.Name, err)
+		}
+	}
+}
+
+// explainFilter prints how a query is evaluated and its verdict on each
+// project. It does not save anything.
+func explainFilter(q *filter.Query, projects []*model.Project) {
+	fmt.Printf("%s %s\n\n", styles.Title("Query:"), q)
+	fmt.Print(q.Explain())
+	fmt.Println()
+
+	matched := make(map[*model.Project]bool)
+	for _, p := range applyFilter(q, projects, false) {
+		matched[p] = true
+	}
+
+	now := time.Now()
+	for _, p := range projects {
+		if matched[p] {
+			fmt.Printf("  %s %s\n", styles.Success("✓"), styles.ProjectName(p.Name))
+			continue
+		}
+		fmt.Printf("  %s %s: %s\n", styles.Error("✗"), styles.ProjectName(p.Name), q.Reason(p, now))
+	}
+	fmt.Printf("\n%d of %d playgrounds match\n", len(matched), len(projects))
+}
+
+// selectProjects returns the project named by --name or, for bulk
+// commands, the projects matching --filter
+func selectProjects(name string) ([]*model.Project, error) {
+	switch {
+	case name != "" && filterExpr != "":
+		return nil, fmt.Errorf("use either --name or --filter, not both")
+	case name != "":
+		p, err := store.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+		return []*model.Project{p}, nil
+	case filterExpr == "":
+		return nil, fmt.Errorf("project name or --filter is required")
+	}
+
+	query, err := parseFilter()
+	if err != nil {
+		return nil, err
+	}
+	projects, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	matched := applyFilter(query, projects, true)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no playgrounds match %s", query)
+	}
+	return matched, nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forkAs    string
+	forkNotes bool
+)
+
+var forkCmd = &cobra.Command{
+	Use:   "fork",
+	Short: "Fork a playground into a new one",
+	Long: `Copy a playground into a new one to try a variation without touching the
+original. The fork gets its own module path and fresh timestamps, keeps the
+original's tags and remembers where it came from.
+Example: goshed fork -n myproject --as myproject-v2 --notes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectName == "" {
+			return fmt.Errorf("project name is required")
+		}
+		if forkAs == "" {
+			return fmt.Errorf("fork name is required")
+		}
+
+		forker, err := storeFeature[project.Forker]("forking")
+		if err != nil {
+			return err
+		}
+		p, err := forker.Fork(projectName, forkAs, forkNotes)
+		if err != nil {
+			return fmt.Errorf("failed to fork project: %w", err)
+		}
+
+		fmt.Printf("%s %s into %s\n",
+			styles.Success("Forked"),
+			styles.ProjectName(projectName),
+			styles.ProjectName(p.Name),
+		)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forkCmd)
+	forkCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to fork (required)")
+	forkCmd.Flags().StringVar(&forkAs, "as", "", "Name of the new playground (required)")
+	forkCmd.Flags().BoolVar(&forkNotes, "notes", false, "Copy the original's notes to the fork")
+	forkCmd.MarkFlagRequired("name")
+	forkCmd.MarkFlagRequired("as")
+	forkCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+}
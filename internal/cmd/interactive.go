@@ -15,7 +15,7 @@ var interactiveCmd = &cobra.Command{
 Example: goshed interactive`,
 	Aliases: []string{"i"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		p := tea.NewProgram(tui.InitialModel())
+		p := tea.NewProgram(tui.InitialModel(store))
 		if _, err := p.Run(); err != nil {
 			return fmt.Errorf("failed to start interactive mode: %w", err)
 		}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -13,47 +14,114 @@ import (
 )
 
 var (
-	showTags    bool
-	filterTag   string
-	sortBy      string
-	reverseSort bool
+	showTags     bool
+	filterTag    string
+	sortBy       string
+	reverseSort  bool
+	showArchive  bool
+	filterProps  []string
+	showActivity bool
 )
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all playgrounds",
 	Long: `List all playgrounds with their details.
-Example: goshed list --tags --sort=accessed`,
+
+--filter selects playgrounds with a query. Terms are joined with and, or and
+not, and grouped with parentheses:
+  tag:web  template:api  name:api-*  module:example.com/*  prop:env=prod
+  git:dirty|clean|none  pinned  external  expired
+  created, accessed, modified, commit or activity >30d (or <, >=, <=)
+  expires<7d  size>100MiB
+A plain word matches playgrounds whose name contains it. --explain shows how
+the query is evaluated against each playground.
+Example: goshed list --tags --sort=accessed --filter 'tag:web and not pinned'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		projects, err := project.List()
+		if showArchive {
+			return listArchived()
+		}
+
+		query, err := parseFilter()
+		if err != nil {
+			return err
+		}
+
+		projects, err := store.List()
 		if err != nil {
 			return fmt.Errorf("failed to list projects: %w", err)
 		}
 
-		// Filter by tag if specified
-		if filterTag != "" {
+		// Lineage is resolved against every project, not just the filtered ones
+		all := projects
+
+		// Filter by tag if specified, normalized the way tags are saved
+		if tags := project.NormalizeTags([]string{filterTag}); len(tags) > 0 {
 			filtered := make([]*model.Project, 0)
 			for _, p := range projects {
-				for _, t := range p.Tags {
-					if t == filterTag {
-						filtered = append(filtered, p)
-						break
-					}
+				if slices.Contains(p.Tags, tags[0]) {
+					filtered = append(filtered, p)
+				}
+			}
+			projects = filtered
+		}
+
+		// Filter by properties, all of which must match
+		for _, arg := range filterProps {
+			f, err := project.ParsePropertyFilter(arg)
+			if err != nil {
+				return err
+			}
+			filtered := make([]*model.Project, 0, len(projects))
+			for _, p := range projects {
+				if f.Match(p) {
+					filtered = append(filtered, p)
 				}
 			}
 			projects = filtered
 		}
 
+		if explainExpr {
+			explainFilter(query, projects)
+			return nil
+		}
+		projects = applyFilter(query, projects, true)
+
+		// Refresh file and commit activity before it is sorted on or shown
+		if (showActivity || sortBy == "activity") && (query == nil || !query.NeedsActivity()) {
+			refreshActivities(projects, true)
+		}
+
+		// Measure projects whose cached size is stale before sorting on it
+		if sortBy == "size" {
+			refreshSizes(projects, true)
+		}
+
 		// Sort projects
-		sort.Slice(projects, func(i, j int) bool {
+		sortProp, bySortProp := strings.CutPrefix(sortBy, "prop:")
+		sort.SliceStable(projects, func(i, j int) bool {
 			var result bool
-			switch sortBy {
-			case "name":
+			switch {
+			case bySortProp:
+				// Projects without the property sort last
+				vi, iok := projects[i].Properties[sortProp]
+				vj, jok := projects[j].Properties[sortProp]
+				if iok != jok {
+					return iok
+				}
+				result = vi < vj
+			case sortBy == "name":
 				result = projects[i].Name < projects[j].Name
-			case "created":
+			case sortBy == "created":
 				result = projects[i].Created.Before(projects[j].Created)
-			case "accessed":
+			case sortBy == "accessed":
 				result = projects[i].LastAccessed.Before(projects[j].LastAccessed)
+			case sortBy == "size":
+				result = cachedSize(projects[i]) < cachedSize(projects[j])
+			case sortBy == "activity":
+				ti, _ := project.LastActivity(projects[i], project.SignalAny)
+				tj, _ := project.LastActivity(projects[j], project.SignalAny)
+				result = ti.Before(tj)
 			default:
 				result = projects[i].Name < projects[j].Name
 			}
@@ -62,6 +130,7 @@ Example: goshed list --tags --sort=accessed`,
 			}
 			return result
 		})
+		project.SortPinnedFirst(projects)
 
 		// Print projects
 		if len(projects) == 0 {
@@ -69,15 +138,33 @@ Example: goshed list --tags --sort=accessed`,
 			return nil
 		}
 
+		statuses := project.GetGitStatuses(projects)
+
 		fmt.Printf("%s\n\n", styles.Title("Found %d playgrounds:", len(projects)))
-		for _, p := range projects {
-			fmt.Printf("%s %s\n", styles.FieldName("Name:"), styles.ProjectName(p.Name))
+		for i, p := range projects {
+			pin := ""
+			if p.Pinned {
+				pin = " 📌"
+			}
+			fmt.Printf("%s %s%s\n", styles.FieldName("Name:"), styles.ProjectName(p.Name), pin)
 			fmt.Printf("  %s %s\n", styles.FieldName("Template:"), p.Template)
+			if p.External {
+				fmt.Printf("  %s %s (external)\n", styles.FieldName("Path:"), p.Path)
+			}
 			fmt.Printf("  %s %s\n", styles.FieldName("Created:"), styles.TimeText(p.Created.Format(time.RFC3339)))
 			fmt.Printf("  %s %s\n", styles.FieldName("Accessed:"), styles.TimeText(p.LastAccessed.Format(time.RFC3339)))
+			if sortBy == "size" && p.Size != nil {
+				fmt.Printf("  %s %s\n", styles.FieldName("Size:"), project.FormatSize(p.Size.Bytes))
+			}
+			if showActivity {
+				fmt.Printf("  %s %s\n", styles.FieldName("Activity:"), activityText(p))
+			}
+			if p.ExpiresAt != nil {
+				fmt.Printf("  %s %s\n", styles.FieldName("Expires:"), expiryText(p))
+			}
 
 			// Add Git status
-			if status, err := project.GetGitStatus(p); err == nil {
+			if status := statuses[i]; status != nil {
 				if status.Initialized {
 					gitStatus := "Clean"
 					if !status.Clean {
@@ -95,6 +182,10 @@ Example: goshed list --tags --sort=accessed`,
 				}
 			}
 
+			if lineage := project.Lineage(p, all); len(lineage) > 0 {
+				fmt.Printf("  %s %s\n", styles.FieldName("Forked from:"), strings.Join(lineage, " ← "))
+			}
+
 			if showTags && len(p.Tags) > 0 {
 				tagList := make([]string, len(p.Tags))
 				for i, tag := range p.Tags {
@@ -102,8 +193,11 @@ Example: goshed list --tags --sort=accessed`,
 				}
 				fmt.Printf("  %s %s\n", styles.FieldName("Tags:"), strings.Join(tagList, ", "))
 			}
-			if p.Notes != "" {
-				fmt.Printf("  %s %s\n", styles.FieldName("Notes:"), p.Notes)
+			if len(p.Properties) > 0 {
+				fmt.Printf("  %s %s\n", styles.FieldName("Properties:"), formatProperties(p.Properties))
+			}
+			if len(p.Notes) > 0 {
+				fmt.Printf("  %s %s\n", styles.FieldName("Notes:"), notesSummary(p))
 			}
 			fmt.Println()
 		}
@@ -112,10 +206,125 @@ Example: goshed list --tags --sort=accessed`,
 	},
 }
 
+// listArchived prints the metadata of the archived projects
+func listArchived() error {
+	archiver, err := storeFeature[project.Archiver]("archiving")
+	if err != nil {
+		return err
+	}
+	archived, err := archiver.ListArchived()
+	if err != nil {
+		return fmt.Errorf("failed to list archived projects: %w", err)
+	}
+
+	if len(archived) == 0 {
+		fmt.Println(styles.Warning("No archived playgrounds found"))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", styles.Title("Found %d archived playgrounds:", len(archived)))
+	for _, a := range archived {
+		p := a.Project
+		fmt.Printf("%s %s\n", styles.FieldName("Name:"), styles.ProjectName(p.Name))
+		fmt.Printf("  %s %s\n", styles.FieldName("Template:"), p.Template)
+		fmt.Printf("  %s %s\n", styles.FieldName("Created:"), styles.TimeText(p.Created.Format(time.RFC3339)))
+		fmt.Printf("  %s %s\n", styles.FieldName("Archived:"), styles.TimeText(a.ArchivedAt.Format(time.RFC3339)))
+		if len(p.Tags) > 0 {
+			fmt.Printf("  %s %s\n", styles.FieldName("Tags:"), strings.Join(p.Tags, ", "))
+		}
+		if len(p.Notes) > 0 {
+			fmt.Printf("  %s %s\n", styles.FieldName("Notes:"), notesSummary(p))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// refreshSizes measures, concurrently, the projects whose cached size is
+// stale, saving the new sizes when save is set
+func refreshSizes(projects []*model.Project, save bool) {
+	now := time.Now()
+	var stale []*model.Project
+	for _, p := range projects {
+		if project.SizeStale(p, now) {
+			stale = append(stale, p)
+		}
+	}
+
+	for i, u := range project.MeasureDiskUsages(stale) {
+		if u == nil {
+			continue
+		}
+		project.SetSize(stale[i], *u, now)
+		if !save {
+			continue
+		}
+		if err := store.Update(stale[i]); err != nil {
+			fmt.Printf("Warning: failed to save size of %s: %v\n", stale[i].Name, err)
+		}
+	}
+}
+
+func cachedSize(p *model.Project) int64 {
+	if p.Size == nil {
+		return 0
+	}
+	return p.Size.Bytes
+}
+
+// activityText summarises how long ago each activity signal last fired
+func activityText(p *model.Project) string {
+	ago := func(t *time.Time) string {
+		if t == nil {
+			return "never"
+		}
+		return project.FormatDuration(time.Since(*t)) + " ago"
+	}
+	return fmt.Sprintf("accessed %s, modified %s, committed %s",
+		ago(&p.LastAccessed), ago(p.LastModified), ago(p.LastCommit))
+}
+
+// expiryText describes how long a project has left before it expires
+func expiryText(p *model.Project) string {
+	left := time.Until(*p.ExpiresAt)
+	if left <= 0 {
+		return styles.Warning("expired %s ago", project.FormatDuration(left))
+	}
+	text := fmt.Sprintf("in %s", project.FormatDuration(left))
+	if left < 24*time.Hour {
+		return styles.Warning("%s", text)
+	}
+	return text
+}
+
+// formatProperties renders properties as sorted key=value pairs
+func formatProperties(props map[string]string) string {
+	pairs := make([]string, 0, len(props))
+	for _, key := range sortedKeys(props) {
+		pairs = append(pairs, key+"="+props[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// notesSummary describes a project's notes by their count and latest entry
+func notesSummary(p *model.Project) string {
+	latest := p.Notes[len(p.Notes)-1]
+	if len(p.Notes) == 1 {
+		return latest.Text
+	}
+	return fmt.Sprintf("%s (+%d earlier)", latest.Text, len(p.Notes)-1)
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolVar(&showTags, "tags", false, "Show project tags")
 	listCmd.Flags().StringVar(&filterTag, "filter-tag", "", "Filter projects by tag")
-	listCmd.Flags().StringVar(&sortBy, "sort", "name", "Sort by: name, created, accessed")
+	listCmd.Flags().StringVar(&sortBy, "sort", "name", "Sort by: name, created, accessed, activity, size or prop:<key>")
+	listCmd.Flags().BoolVar(&showActivity, "activity", false, "Show file, commit and goshed activity")
+	listCmd.Flags().StringArrayVar(&filterProps, "prop", nil, "Filter projects by property, as key or key=value (repeatable)")
+	listCmd.Flags().StringVar(&filterExpr, "filter", "", filterHelp)
+	listCmd.Flags().BoolVar(&explainExpr, "explain", false, "Show how the --filter query is evaluated instead of listing")
 	listCmd.Flags().BoolVar(&reverseSort, "reverse", false, "Reverse sort order")
+	listCmd.Flags().BoolVar(&showArchive, "archived", false, "List archived playgrounds instead")
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateDryRun bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade project metadata to the current schema",
+	Long: `Upgrade every playground's .goshed.json to the current schema version.
+The original metadata is kept as .goshed.json.bak.
+Example: goshed migrate --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, ok := store.(project.Migrator)
+		if !ok {
+			fmt.Println(styles.Success("Project metadata is up to date"))
+			return nil
+		}
+
+		results, err := m.Migrate(migrateDryRun)
+		if err != nil {
+			return fmt.Errorf("failed to migrate projects: %w", err)
+		}
+
+		if len(results) == 0 {
+			fmt.Println(styles.Success("Project metadata is up to date"))
+			return nil
+		}
+
+		verb := "Migrated"
+		if migrateDryRun {
+			verb = "Would migrate"
+		}
+		for _, r := range results {
+			fmt.Printf("%s %s %s\n",
+				styles.Success(verb),
+				styles.ProjectName(r.Name),
+				styles.Header("(v%d -> v%d)", r.From, r.To),
+			)
+			for _, change := range r.Applied {
+				fmt.Printf("  - %s\n", change)
+			}
+		}
+
+		fmt.Printf("\n%s %d projects\n", verb, len(results))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Report which projects would change without writing anything")
+}
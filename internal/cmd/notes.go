@@ -2,54 +2,263 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
 
+	"github.com/crazywolf132/goshed/internal/model"
 	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/crazywolf132/goshed/internal/styles"
 	"github.com/spf13/cobra"
 )
 
 var (
-	noteText string
+	noteText     string
+	noteBody     string
+	noteEdit     bool
+	showNoteBody bool
 )
 
 var notesCmd = &cobra.Command{
 	Use:   "notes",
 	Short: "Manage project notes",
-	Long: `Add or view notes for a project.
+	Long: `Keep a journal of timestamped notes for a project. Each note has a one-line
+text and an optional markdown body, stored in NOTES.md in the project.
+Without a subcommand the notes are listed; -t adds a note.
 Example: goshed notes -n myproject [-t "My note text"]`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if projectName == "" {
-			return fmt.Errorf("project name is required")
+		if noteText != "" {
+			notes, err := storeFeature[project.Notebook]("notes")
+			if err != nil {
+				return err
+			}
+			note, err := notes.AddNote(projectName, noteText, "")
+			if err != nil {
+				return fmt.Errorf("failed to add note: %w", err)
+			}
+			fmt.Printf("%s #%d to %s\n", styles.Success("Added note"), note.ID, styles.ProjectName(projectName))
+		}
+		return listNotes(projectName, false)
+	},
+}
+
+var notesAddCmd = &cobra.Command{
+	Use:   "add [text]",
+	Short: "Add a note",
+	Long: `Add a timestamped note. Without text, or with --edit, $EDITOR is opened on
+a temporary file: the first line is the note and the rest its markdown body.
+Example: goshed notes add -n myproject "Tried buffered channels" --body "..."`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text, body := strings.Join(args, " "), noteBody
+		if text == "" || noteEdit {
+			var err error
+			text, body, err = editNote(text, body)
+			if err != nil {
+				return err
+			}
+		}
+
+		notes, err := storeFeature[project.Notebook]("notes")
+		if err != nil {
+			return err
+		}
+		note, err := notes.AddNote(projectName, text, body)
+		if err != nil {
+			return fmt.Errorf("failed to add note: %w", err)
+		}
+		fmt.Printf("%s #%d to %s\n", styles.Success("Added note"), note.ID, styles.ProjectName(projectName))
+		return nil
+	},
+}
+
+var notesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listNotes(projectName, showNoteBody)
+	},
+}
+
+var notesEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a note in $EDITOR",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseNoteID(args[0])
+		if err != nil {
+			return err
 		}
 
-		p, err := project.Get(projectName)
+		p, err := store.Get(projectName)
 		if err != nil {
 			return fmt.Errorf("failed to get project: %w", err)
 		}
+		note, ok := findNote(p, id)
+		if !ok {
+			return fmt.Errorf("project %s has no note %d", projectName, id)
+		}
+		notes, err := storeFeature[project.Notebook]("notes")
+		if err != nil {
+			return err
+		}
+		bodies, err := notes.NoteBodies(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to read notes: %w", err)
+		}
 
-		// If note text is provided, update the notes
-		if noteText != "" {
-			p.Notes = noteText
-			if err := project.Update(p); err != nil {
-				return fmt.Errorf("failed to update project notes: %w", err)
-			}
-			fmt.Printf("%s %s\n", styles.Success("Updated notes for"), styles.ProjectName(p.Name))
+		text, body, err := editNote(note.Text, bodies[id])
+		if err != nil {
+			return err
+		}
+		if text == note.Text && body == bodies[id] {
+			fmt.Println(styles.Warning("Note unchanged"))
+			return nil
 		}
 
-		// Display current notes
-		if p.Notes != "" {
-			fmt.Printf("%s %s\n", styles.FieldName("Notes:"), p.Notes)
-		} else {
-			fmt.Printf("%s\n", styles.Warning("No notes found"))
+		if _, err := notes.EditNote(projectName, id, text, body); err != nil {
+			return fmt.Errorf("failed to edit note: %w", err)
 		}
+		fmt.Printf("%s #%d of %s\n", styles.Success("Updated note"), id, styles.ProjectName(projectName))
+		return nil
+	},
+}
 
+var notesDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a note",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseNoteID(args[0])
+		if err != nil {
+			return err
+		}
+
+		notes, err := storeFeature[project.Notebook]("notes")
+		if err != nil {
+			return err
+		}
+		if err := notes.DeleteNote(projectName, id); err != nil {
+			return fmt.Errorf("failed to delete note: %w", err)
+		}
+		fmt.Printf("%s #%d from %s\n", styles.Success("Deleted note"), id, styles.ProjectName(projectName))
 		return nil
 	},
 }
 
+// listNotes prints a project's notes, with their bodies if showBody is set
+func listNotes(name string, showBody bool) error {
+	p, err := store.Get(name)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if len(p.Notes) == 0 {
+		fmt.Printf("%s\n", styles.Warning("No notes found"))
+		return nil
+	}
+
+	var bodies map[int]string
+	if showBody {
+		notes, err := storeFeature[project.Notebook]("notes")
+		if err != nil {
+			return err
+		}
+		if bodies, err = notes.NoteBodies(name); err != nil {
+			return fmt.Errorf("failed to read notes: %w", err)
+		}
+	}
+
+	for _, n := range p.Notes {
+		marker := ""
+		if n.HasBody && !showBody {
+			marker = " [+]"
+		}
+		fmt.Printf("%s %s  %s%s\n",
+			styles.FieldName("#%d", n.ID),
+			styles.TimeText(n.Created.Format("2006-01-02 15:04")),
+			n.Text,
+			marker,
+		)
+		if body := bodies[n.ID]; body != "" {
+			for _, line := range strings.Split(body, "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// editNote opens $EDITOR on a note and returns the edited text and body.
+// The first non-empty line is the note's text; the rest is its body.
+func editNote(text, body string) (string, string, error) {
+	f, err := os.CreateTemp("", "goshed-note-*.md")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	content := text + "\n"
+	if body != "" {
+		content += "\n" + body + "\n"
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	f.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	execCmd := exec.Command(fields[0], append(fields[1:], f.Name())...)
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+	if err := execCmd.Run(); err != nil {
+		return "", "", fmt.Errorf("failed to run editor: %w", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read temporary file: %w", err)
+	}
+
+	edited := strings.TrimSpace(string(data))
+	text, body, _ = strings.Cut(edited, "\n")
+	return strings.TrimSpace(text), strings.TrimSpace(body), nil
+}
+
+func findNote(p *model.Project, id int) (model.Note, bool) {
+	for _, n := range p.Notes {
+		if n.ID == id {
+			return n, true
+		}
+	}
+	return model.Note{}, false
+}
+
+func parseNoteID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid note id %q", s)
+	}
+	return id, nil
+}
+
 func init() {
 	rootCmd.AddCommand(notesCmd)
-	notesCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+	notesCmd.AddCommand(notesAddCmd, notesListCmd, notesEditCmd, notesDeleteCmd)
+
+	notesCmd.PersistentFlags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+	notesCmd.MarkPersistentFlagRequired("name")
+	notesCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
 	notesCmd.Flags().StringVarP(&noteText, "text", "t", "", "Note text to add")
-	notesCmd.MarkFlagRequired("name")
+
+	notesAddCmd.Flags().StringVarP(&noteBody, "body", "b", "", "Markdown body of the note")
+	notesAddCmd.Flags().BoolVarP(&noteEdit, "edit", "e", false, "Write the note in $EDITOR")
+	notesListCmd.Flags().BoolVar(&showNoteBody, "full", false, "Show the body of each note")
 }
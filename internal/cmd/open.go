@@ -6,7 +6,6 @@ import (
 	"os/exec"
 	"time"
 
-	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,14 +21,14 @@ Example: goshed open -n myproject`,
 		}
 
 		// Get project
-		p, err := project.Get(projectName)
+		p, err := store.Get(projectName)
 		if err != nil {
 			return fmt.Errorf("failed to get project: %w", err)
 		}
 
 		// Update last accessed time
 		p.LastAccessed = time.Now()
-		if err := project.Update(p); err != nil {
+		if err := store.Update(p); err != nil {
 			return fmt.Errorf("failed to update project: %w", err)
 		}
 
@@ -57,4 +56,5 @@ func init() {
 	rootCmd.AddCommand(openCmd)
 	openCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to open (required)")
 	openCmd.MarkFlagRequired("name")
+	openCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
 }
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var forceRemove bool
+
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Protect a playground from cleanup",
+	Long: `Pin a playground so that 'goshed clean' never removes it. Pinned playgrounds
+sort first, and removing one requires --force. --filter pins every playground
+matching a query instead (see 'goshed list --help').
+Example: goshed pin -n myproject`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(projectName, true)
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin",
+	Short: "Remove a playground's cleanup protection",
+	Long: `Unpin a playground so that it can be cleaned up again. --filter unpins
+every playground matching a query instead.
+Example: goshed unpin --filter 'tag:done'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(projectName, false)
+	},
+}
+
+// setPinned pins or unpins the named project, or the projects matching
+// --filter
+func setPinned(name string, pinned bool) error {
+	projects, err := selectProjects(name)
+	if err != nil {
+		return err
+	}
+
+	action := "Pinned"
+	if !pinned {
+		action = "Unpinned"
+	}
+	for _, p := range projects {
+		if p.Pinned == pinned {
+			fmt.Printf("%s is already %s\n", styles.ProjectName(p.Name), strings.ToLower(action))
+			continue
+		}
+
+		p.Pinned = pinned
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+		fmt.Printf("%s %s\n", styles.Success(action), styles.ProjectName(p.Name))
+	}
+	return nil
+}
+
+// checkPinned refuses to remove a pinned project unless force is set
+func checkPinned(p *model.Project, force bool) error {
+	if p.Pinned && !force {
+		return fmt.Errorf("project %s is pinned; use --force to remove it anyway", p.Name)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd, unpinCmd)
+	for _, c := range []*cobra.Command{pinCmd, unpinCmd} {
+		c.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground")
+		c.Flags().StringVar(&filterExpr, "filter", "", "Apply to every playground matching this query instead")
+		c.RegisterFlagCompletionFunc("name", completeProjectNames)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/crazywolf132/goshed/internal/styles"
 	"github.com/spf13/cobra"
 )
@@ -26,10 +25,13 @@ Example: goshed promote -n myproject -d ~/projects/myproject`,
 		}
 
 		// Get project
-		p, err := project.Get(projectName)
+		p, err := store.Get(projectName)
 		if err != nil {
 			return fmt.Errorf("failed to get project: %w", err)
 		}
+		if err := checkPinned(p, forceRemove); err != nil {
+			return err
+		}
 
 		// If destination is not specified, use current directory
 		if destination == "" {
@@ -51,12 +53,12 @@ Example: goshed promote -n myproject -d ~/projects/myproject`,
 		}
 
 		// Copy project files to destination
-		if err := project.CopyTo(p, destination); err != nil {
+		if err := store.CopyTo(p, destination); err != nil {
 			return fmt.Errorf("failed to copy project: %w", err)
 		}
 
 		// Remove project from GoShed
-		if err := project.Remove(p.Name); err != nil {
+		if err := store.Remove(p.Name, fmt.Sprintf("promoted to %s", destination)); err != nil {
 			return fmt.Errorf("failed to remove project from GoShed: %w", err)
 		}
 
@@ -73,5 +75,7 @@ func init() {
 	rootCmd.AddCommand(promoteCmd)
 	promoteCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to promote (required)")
 	promoteCmd.Flags().StringVarP(&destination, "destination", "d", "", "Destination directory (defaults to current directory)")
+	promoteCmd.Flags().BoolVar(&forceRemove, "force", false, "Promote the playground even if it is pinned")
 	promoteCmd.MarkFlagRequired("name")
+	promoteCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
 }
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var propCmd = &cobra.Command{
+	Use:   "prop",
+	Short: "Manage project properties",
+	Long: `Attach key/value properties such as ticket=ABC-123 or owner=team-x to a
+playground. 'goshed list' can filter and sort on them.
+Example: goshed prop set -n myproject ticket=ABC-123 status=blocked`,
+}
+
+var propSetCmd = &cobra.Command{
+	Use:   "set key=value...",
+	Short: "Set one or more properties",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		props := make(map[string]string, len(args))
+		for _, arg := range args {
+			key, value, err := project.ParseProperty(arg)
+			if err != nil {
+				return err
+			}
+			props[key] = value
+		}
+
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+		if p.Properties == nil {
+			p.Properties = make(map[string]string, len(props))
+		}
+		for key, value := range props {
+			p.Properties[key] = value
+		}
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project properties: %w", err)
+		}
+
+		fmt.Printf("%s %d properties on %s\n", styles.Success("Set"), len(props), styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+var propGetCmd = &cobra.Command{
+	Use:   "get key",
+	Short: "Print the value of a property",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		value, ok := p.Properties[args[0]]
+		if !ok {
+			return fmt.Errorf("project %s has no property %s", p.Name, args[0])
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var propUnsetCmd = &cobra.Command{
+	Use:   "unset key...",
+	Short: "Remove one or more properties",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		removed := 0
+		for _, key := range args {
+			if _, ok := p.Properties[key]; ok {
+				delete(p.Properties, key)
+				removed++
+			}
+		}
+		if removed == 0 {
+			fmt.Println(styles.Warning("No matching properties found"))
+			return nil
+		}
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project properties: %w", err)
+		}
+
+		fmt.Printf("%s %d properties from %s\n", styles.Success("Removed"), removed, styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+var propListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List a project's properties",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		if len(p.Properties) == 0 {
+			fmt.Println(styles.Warning("No properties found"))
+			return nil
+		}
+		for _, key := range sortedKeys(p.Properties) {
+			fmt.Printf("%s %s\n", styles.FieldName("%s:", key), p.Properties[key])
+		}
+		return nil
+	},
+}
+
+// sortedKeys returns the keys of a property map in order
+func sortedKeys(props map[string]string) []string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// completePropertyKeys completes the property keys of the project named
+// by --name
+func completePropertyKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := openStore(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	p, err := store.Get(projectName)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sortedKeys(p.Properties), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(propCmd)
+	propCmd.AddCommand(propSetCmd, propGetCmd, propUnsetCmd, propListCmd)
+
+	propCmd.PersistentFlags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+	propCmd.MarkPersistentFlagRequired("name")
+	propCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+
+	propGetCmd.ValidArgsFunction = completePropertyKeys
+	propUnsetCmd.ValidArgsFunction = completePropertyKeys
+}
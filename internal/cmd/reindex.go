@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the project index",
+	Long: `Rebuild the workspace's project index from each playground's metadata.
+The index is kept up to date automatically; use this if it ever looks wrong.
+Example: goshed reindex`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ix, ok := store.(project.Indexer); ok {
+			if err := ix.Reindex(); err != nil {
+				return fmt.Errorf("failed to rebuild index: %w", err)
+			}
+		}
+
+		projects, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		fmt.Printf("%s %d projects\n", styles.Success("Indexed"), len(projects))
+		return nil
+	},
+}
+
+// completeProjectNames completes a --name flag with the projects in the
+// workspace index
+func completeProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := openStore(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	projects, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(projects))
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
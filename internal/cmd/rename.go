@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameTo string
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Rename a playground",
+	Long: `Rename a playground's directory and metadata. If its module path was
+generated from the playground name, the module path and every import of it
+are rewritten too. Adopted playgrounds registered by reference keep their
+module; only the registration is renamed.
+Example: goshed rename -n myproject --to newname`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectName == "" {
+			return fmt.Errorf("project name is required")
+		}
+		if renameTo == "" {
+			return fmt.Errorf("new name is required")
+		}
+
+		renamer, err := storeFeature[project.Renamer]("renaming")
+		if err != nil {
+			return err
+		}
+		p, err := renamer.Rename(projectName, renameTo)
+		if err != nil {
+			return fmt.Errorf("failed to rename project: %w", err)
+		}
+
+		fmt.Printf("%s %s to %s\n",
+			styles.Success("Renamed"),
+			styles.ProjectName(projectName),
+			styles.ProjectName(p.Name),
+		)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renameCmd)
+	renameCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to rename (required)")
+	renameCmd.Flags().StringVar(&renameTo, "to", "", "New name for the playground (required)")
+	renameCmd.MarkFlagRequired("name")
+	renameCmd.MarkFlagRequired("to")
+	renameCmd.RegisterFlagCompletionFunc("name", completeProjectNames)
+}
@@ -1,8 +1,24 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/crazywolf132/goshed/internal/config"
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	workspaceOverride string
+
+	// store is the project store commands operate on. Unless one is
+	// injected with SetStore, it is opened for the active workspace before
+	// any command runs.
+	store project.Store
 )
 
 var rootCmd = &cobra.Command{
@@ -10,15 +26,104 @@ var rootCmd = &cobra.Command{
 	Short: "GoShed - A playground manager for Go",
 	Long: `GoShed helps you manage Go playgrounds and experiments.
 Create, organize, and maintain your Go code snippets with ease.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := openStore(); err != nil {
+			return err
+		}
+		if interactive(cmd) {
+			warnExpiring()
+		}
+		return nil
+	},
 }
 
 func Execute() error {
 	return rootCmd.Execute()
 }
 
+// SetStore injects the project store used by all commands, replacing the
+// on-disk workspace store. It lets tests and embedding tools run goshed
+// against their own backend.
+func SetStore(s project.Store) {
+	store = s
+}
+
+// openStore binds the project store to the active workspace, honouring the
+// --workspace flag over the configured default.
+func openStore() error {
+	if store != nil {
+		return nil
+	}
+
+	workspace := viper.GetString("workspace")
+	if workspaceOverride != "" {
+		workspace = workspaceOverride
+	}
+
+	root := config.WorkspaceDir(workspace)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return fmt.Errorf("workspace %s does not exist", workspace)
+	}
+
+	store = project.NewFSStore(root, config.ArchiveDir(workspace))
+	return nil
+}
+
+// interactive reports whether cmd was run by a person at a terminal, as
+// opposed to shell completion or a script
+func interactive(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return false
+		}
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// warnExpiring prints a warning to stderr for every project that has
+// expired or will expire within the next day
+func warnExpiring() {
+	projects, err := store.List()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for _, p := range project.ExpiringWithin(projects, now, 24*time.Hour) {
+		if project.Expired(p, now) {
+			fmt.Fprintf(os.Stderr, "%s %s expired %s ago and will be moved to the trash by the next clean\n",
+				styles.Warning("Warning:"), p.Name, project.FormatDuration(now.Sub(*p.ExpiresAt)))
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s %s expires in %s\n",
+			styles.Warning("Warning:"), p.Name, project.FormatDuration(p.ExpiresAt.Sub(now)))
+	}
+}
+
+// storeFeature returns the store as the optional interface T, or an error
+// naming the feature the store does not support
+func storeFeature[T any](feature string) (T, error) {
+	f, ok := store.(T)
+	if !ok {
+		return f, fmt.Errorf("this store does not support %s", feature)
+	}
+	return f, nil
+}
+
+// lockWorkspace takes the workspace lock for a bulk operation, if the store
+// supports it. The returned function releases the lock.
+func lockWorkspace() (func(), error) {
+	l, ok := store.(project.WorkspaceLocker)
+	if !ok {
+		return func() {}, nil
+	}
+	return l.LockWorkspace()
+}
+
 func init() {
 	cobra.OnInitialize(config.InitConfig)
 
-	// Add persistent flags here if needed
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.goshed.yaml)")
+	rootCmd.PersistentFlags().StringVar(&workspaceOverride, "workspace", "", "Workspace to use for this command (defaults to the current workspace)")
 }
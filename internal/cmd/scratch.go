@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var scratchEditor bool
+
+var scratchCmd = &cobra.Command{
+	Use:   "scratch",
+	Short: "Start a throwaway playground",
+	Long: `Create a temporary playground and open a shell in it, or your editor
+with --editor. When the shell or editor exits, choose whether to keep the
+playground, optionally under a new name, or discard it to the trash.
+Scratch playgrounds that have not been kept never show up in list.
+With --editor, GUI editors must wait for the window to close, e.g.
+editor: code --wait.
+Example: goshed scratch -t web`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := project.GenerateName(func(name string) bool {
+			return project.NameTaken(store, name)
+		})
+
+		p := &model.Project{
+			Name:         name,
+			Created:      time.Now(),
+			LastAccessed: time.Now(),
+			Template:     templateName,
+			Module:       project.DefaultModulePath(viper.GetString("module_prefix"), name),
+			Scratch:      true,
+		}
+		if err := store.Create(p); err != nil {
+			return fmt.Errorf("%s: %w", styles.Error("%s", "Failed to create scratch playground"), err)
+		}
+		p, err := store.Get(name)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		if scratchEditor {
+			fmt.Printf("Opening scratch playground %s in your editor\n", styles.ProjectName(p.Name))
+		} else {
+			fmt.Printf("Scratch playground %s: exit the shell when you are done\n", styles.ProjectName(p.Name))
+		}
+		if err := runScratchSession(p); err != nil {
+			// Still ask, so the playground is not left behind
+			fmt.Println(styles.Warning("%v", err))
+		}
+
+		return finishScratch(bufio.NewReader(os.Stdin), p)
+	},
+}
+
+// runScratchSession opens a shell in the project directory, or the editor
+// on it, and waits for it to exit
+func runScratchSession(p *model.Project) error {
+	var execCmd *exec.Cmd
+	if scratchEditor {
+		fields := strings.Fields(viper.GetString("editor"))
+		if len(fields) == 0 {
+			fields = []string{"code", "--wait"}
+		}
+		execCmd = exec.Command(fields[0], append(fields[1:], p.Path)...)
+	} else {
+		execCmd = exec.Command(userShell())
+		execCmd.Dir = p.Path
+		execCmd.Env = append(os.Environ(), "GOSHED_SCRATCH="+p.Name)
+	}
+	execCmd.Stdin = os.Stdin
+	execCmd.Stdout = os.Stdout
+	execCmd.Stderr = os.Stderr
+
+	if err := execCmd.Run(); err != nil {
+		// A shell exits with the status of its last command
+		if _, ok := err.(*exec.ExitError); ok && !scratchEditor {
+			return nil
+		}
+		return fmt.Errorf("failed to run %s: %w", execCmd.Path, err)
+	}
+	return nil
+}
+
+// userShell returns the user's login shell
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		if shell := os.Getenv("COMSPEC"); shell != "" {
+			return shell
+		}
+		return "cmd.exe"
+	}
+	return "/bin/sh"
+}
+
+// finishScratch asks whether to keep a scratch project, and under which
+// name, or moves it to the trash
+func finishScratch(in *bufio.Reader, p *model.Project) error {
+	answer, err := prompt(in, fmt.Sprintf("Keep %s? [y/N] ", styles.ProjectName(p.Name)))
+	if err != nil || !strings.HasPrefix(strings.ToLower(answer), "y") {
+		if err := store.Remove(p.Name, "scratch: discarded"); err != nil {
+			return fmt.Errorf("failed to discard scratch playground: %w", err)
+		}
+		fmt.Printf("%s %s (goshed trash restore -n %s brings it back)\n",
+			styles.Success("Discarded"), styles.ProjectName(p.Name), p.Name)
+		return nil
+	}
+
+	for {
+		name, err := prompt(in, fmt.Sprintf("Name [%s]: ", p.Name))
+		if err != nil || name == "" || name == p.Name {
+			break
+		}
+		if err := project.ValidateName(name); err != nil {
+			fmt.Println(styles.Error("%v", err))
+			continue
+		}
+		renamer, err := storeFeature[project.Renamer]("renaming")
+		if err != nil {
+			fmt.Println(styles.Error("%v", err))
+			break
+		}
+		renamed, err := renamer.Rename(p.Name, name)
+		if err != nil {
+			fmt.Println(styles.Error("%v", err))
+			continue
+		}
+		p = renamed
+		break
+	}
+
+	p, err = store.Get(p.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	p.Scratch = false
+	p.LastAccessed = time.Now()
+	if err := store.Update(p); err != nil {
+		return fmt.Errorf("failed to keep scratch playground: %w", err)
+	}
+	fmt.Printf("%s %s\n", styles.Success("Kept playground:"), styles.ProjectName(p.Name))
+	return nil
+}
+
+// prompt prints a question and reads a line of input. It returns io.EOF
+// when there is no more input.
+func prompt(in *bufio.Reader, question string) (string, error) {
+	fmt.Print(question)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func init() {
+	rootCmd.AddCommand(scratchCmd)
+	scratchCmd.Flags().StringVarP(&templateName, "template", "t", "basic", "Template to use (basic, web, cli)")
+	scratchCmd.Flags().BoolVar(&scratchEditor, "editor", false, "Open the editor instead of a shell")
+}
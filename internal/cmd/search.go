@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/crazywolf132/goshed/internal/search"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchRegex bool
+	searchLang  string
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search code, notes, tags and names across playgrounds",
+	Long: `Search the Go source, go.mod and NOTES.md files of every playground,
+along with their names, tags and notes. Files ignored by .gitignore are
+skipped. The query is matched literally unless --regex is given, and is
+case-insensitive unless it contains an upper-case letter.
+
+With --lang go only the names of functions, methods (as Type.Method) and
+types are searched.
+Example: goshed search --lang go -r '^Handle'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := search.Options{}
+		switch searchLang {
+		case "":
+		case "go":
+			opts.Symbols = true
+		default:
+			return fmt.Errorf("unsupported language %q: only go is supported", searchLang)
+		}
+
+		re, err := searchPattern(args[0], searchRegex)
+		if err != nil {
+			return err
+		}
+
+		projects, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		matches, err := search.Search(projects, re, opts)
+		if err != nil {
+			fmt.Printf("%s %v\n", styles.Warning("Warning:"), err)
+		}
+		if len(matches) == 0 {
+			fmt.Println(styles.Warning("No matches found"))
+			return nil
+		}
+
+		for _, m := range matches {
+			fmt.Printf("%s %s: %s\n", styles.ProjectName(m.Project), searchLocation(m), highlight(m.Text, m.Spans))
+		}
+		return nil
+	},
+}
+
+// searchPattern compiles a search query, quoting it unless it is a regular
+// expression. Queries without upper-case letters match any case.
+func searchPattern(query string, isRegex bool) (*regexp.Regexp, error) {
+	expr := query
+	if !isRegex {
+		expr = regexp.QuoteMeta(query)
+	}
+	if !strings.ContainsFunc(query, unicode.IsUpper) {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return re, nil
+}
+
+// searchLocation describes where a match was found: file and line for file
+// matches, or what kind of metadata matched
+func searchLocation(m search.Match) string {
+	if m.File == "" {
+		return styles.FieldName("[%s]", m.Kind)
+	}
+	return styles.Path("%s:%d", m.File, m.Line)
+}
+
+// highlight colours the matched ranges of text
+func highlight(text string, spans [][]int) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		b.WriteString(text[last:span[0]])
+		b.WriteString(styles.Match("%s", text[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(text[last:])
+	return strings.TrimSpace(b.String())
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().BoolVarP(&searchRegex, "regex", "r", false, "Treat the query as a regular expression")
+	searchCmd.Flags().StringVar(&searchLang, "lang", "", "Search language symbols instead of text (go)")
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotForce bool
+	snapshotStat  bool
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and roll back a playground's state",
+	Long: `Save the state of a playground's files before trying something risky and
+roll back to it later. Playgrounds with a git repository keep snapshots as
+commits on a hidden ref, leaving branches, the index and HEAD alone; others
+keep compressed copies in .goshed-snapshots. Metadata and NOTES.md are not
+part of snapshots.
+Example: goshed snapshot save -n myproject "works"`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save [label]",
+	Short: "Save a snapshot of a playground",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshotter, err := storeFeature[project.Snapshotter]("snapshots")
+		if err != nil {
+			return err
+		}
+
+		label := ""
+		if len(args) > 0 {
+			label = args[0]
+		}
+		snap, err := snapshotter.SaveSnapshot(projectName, label)
+		if err != nil {
+			return err
+		}
+		touchProject(projectName)
+
+		fmt.Printf("%s %s of %s\n", styles.Success("Saved snapshot"), snapshotName(snap), styles.ProjectName(projectName))
+		return nil
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List a playground's snapshots, newest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshotter, err := storeFeature[project.Snapshotter]("snapshots")
+		if err != nil {
+			return err
+		}
+
+		snapshots, err := snapshotter.ListSnapshots(projectName)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			fmt.Println(styles.Warning("%s has no snapshots", projectName))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, snap := range snapshots {
+			kind := "copy"
+			if snap.Git {
+				kind = "git"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				snap.ShortID(),
+				styles.TimeText("%s ago", project.FormatDuration(time.Since(snap.Created))),
+				kind,
+				snap.Label,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot]",
+	Short: "Roll a playground back to a snapshot",
+	Long: `Put a playground's files back as they were in a snapshot, picked by ID,
+ID prefix or label, or the newest one. Files created since are removed.
+Changes that are in neither a git commit nor a snapshot are not
+overwritten unless --force is given.
+Example: goshed snapshot restore -n myproject works`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshotter, err := storeFeature[project.Snapshotter]("snapshots")
+		if err != nil {
+			return err
+		}
+
+		snap, err := snapshotter.RestoreSnapshot(projectName, snapshotArg(args), snapshotForce)
+		if errors.Is(err, project.ErrUnsavedChanges) {
+			return fmt.Errorf("%w; save a snapshot first or use --force to discard them", err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", projectName, err)
+		}
+		touchProject(projectName)
+
+		fmt.Printf("%s %s to snapshot %s\n", styles.Success("Restored"), styles.ProjectName(projectName), snapshotName(snap))
+		return nil
+	},
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:   "diff [snapshot]",
+	Short: "Show what changed since a snapshot",
+	Long: `Compare a snapshot, picked by ID, ID prefix or label, or the newest one,
+with the playground's current files.
+Example: goshed snapshot diff -n myproject works --stat`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshotter, err := storeFeature[project.Snapshotter]("snapshots")
+		if err != nil {
+			return err
+		}
+
+		snap, changed, err := snapshotter.DiffSnapshot(projectName, snapshotArg(args), snapshotStat, os.Stdout)
+		if err != nil {
+			return fmt.Errorf("failed to diff %s: %w", projectName, err)
+		}
+		if !changed {
+			fmt.Printf("No changes since snapshot %s\n", snapshotName(snap))
+		}
+		return nil
+	},
+}
+
+// snapshotArg returns the snapshot named on the command line, if any
+func snapshotArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// snapshotName describes a snapshot by its ID and label
+func snapshotName(snap *project.Snapshot) string {
+	if snap.Label == "" {
+		return snap.ShortID()
+	}
+	return fmt.Sprintf("%s (%s)", snap.ShortID(), snap.Label)
+}
+
+// touchProject records that a command used the project
+func touchProject(name string) {
+	p, err := store.Get(name)
+	if err == nil {
+		p.LastAccessed = time.Now()
+		err = store.Update(p)
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to update %s: %v\n", name, err)
+	}
+}
+
+// completeSnapshots completes the labels and IDs of the --name project's
+// snapshots
+func completeSnapshots(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || openStore() != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	snapshotter, ok := store.(project.Snapshotter)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	name, _ := cmd.Flags().GetString("name")
+	snapshots, err := snapshotter.ListSnapshots(name)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var refs []string
+	for _, snap := range snapshots {
+		if snap.Label != "" {
+			refs = append(refs, snap.Label)
+		}
+		refs = append(refs, snap.ShortID())
+	}
+	return refs, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd, snapshotListCmd, snapshotRestoreCmd, snapshotDiffCmd)
+
+	for _, c := range []*cobra.Command{snapshotSaveCmd, snapshotListCmd, snapshotRestoreCmd, snapshotDiffCmd} {
+		c.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+		c.MarkFlagRequired("name")
+		c.RegisterFlagCompletionFunc("name", completeProjectNames)
+	}
+	snapshotRestoreCmd.ValidArgsFunction = completeSnapshots
+	snapshotDiffCmd.ValidArgsFunction = completeSnapshots
+
+	snapshotRestoreCmd.Flags().BoolVarP(&snapshotForce, "force", "f", false, "Overwrite changes that are not in a commit or snapshot")
+	snapshotDiffCmd.Flags().BoolVar(&snapshotStat, "stat", false, "Show a summary of changed files instead of a patch")
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage playground tags",
+	Long: `Add tags to and remove them from a playground, rename a tag everywhere
+and see which tags are in use. Tags are trimmed, lowercased and
+deduplicated whenever they are saved.
+Example: goshed tag add -n myproject web api`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add tag...",
+	Short: "Add tags to a playground",
+	Long: `Add tags to a playground. Tags may also be given comma-separated.
+Example: goshed tag add -n myproject web api`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		before := len(project.NormalizeTags(p.Tags))
+		p.Tags = project.NormalizeTags(append(p.Tags, project.ParseTags(args...)...))
+		added := len(p.Tags) - before
+		if added == 0 {
+			fmt.Printf("%s already has those tags\n", styles.ProjectName(p.Name))
+			return nil
+		}
+
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project tags: %w", err)
+		}
+		fmt.Printf("%s %d tags to %s\n", styles.Success("Added"), added, styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove tag...",
+	Short: "Remove tags from a playground",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := store.Get(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to get project: %w", err)
+		}
+
+		remove := project.ParseTags(args...)
+		tags := project.NormalizeTags(p.Tags)
+		kept := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+			return slices.Contains(remove, tag)
+		})
+		removed := len(tags) - len(kept)
+		if removed == 0 {
+			fmt.Println(styles.Warning("No matching tags found"))
+			return nil
+		}
+
+		p.Tags = kept
+		p.LastAccessed = time.Now()
+		if err := store.Update(p); err != nil {
+			return fmt.Errorf("failed to update project tags: %w", err)
+		}
+		fmt.Printf("%s %d tags from %s\n", styles.Success("Removed"), removed, styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename old new",
+	Short: "Rename a tag on every playground",
+	Long: `Replace a tag with another on every playground that has it. Playgrounds
+that already have the new tag simply lose the old one. Scratch playgrounds
+that have not been kept are left alone.
+Example: goshed tag rename http web`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from := project.ParseTags(args[0])
+		to := project.ParseTags(args[1])
+		if len(from) != 1 || len(to) != 1 {
+			return fmt.Errorf("tag names must be a single non-empty tag")
+		}
+		if from[0] == to[0] {
+			return fmt.Errorf("tag %s would be renamed to itself", from[0])
+		}
+
+		unlock, err := lockWorkspace()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		projects, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		renamed := 0
+		for _, p := range projects {
+			tags := project.NormalizeTags(p.Tags)
+			i := slices.Index(tags, from[0])
+			if i < 0 {
+				continue
+			}
+			tags[i] = to[0]
+			// Drops the new tag's duplicate if the project already had it
+			p.Tags = project.NormalizeTags(tags)
+			if err := store.Update(p); err != nil {
+				fmt.Printf("Warning: failed to update %s: %v\n", p.Name, err)
+				continue
+			}
+			renamed++
+		}
+
+		if renamed == 0 {
+			fmt.Println(styles.Warning("No playgrounds have tag %s", from[0]))
+			return nil
+		}
+		fmt.Printf("%s %s to %s on %d playgrounds\n",
+			styles.Success("Renamed"),
+			styles.TagText("%s", from[0]),
+			styles.TagText("%s", to[0]),
+			renamed,
+		)
+		return nil
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tags in use and how many playgrounds have each",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projects, err := store.List()
+		if err != nil {
+			return fmt.Errorf("failed to list projects: %w", err)
+		}
+
+		counts := tagCounts(projects)
+		if len(counts) == 0 {
+			fmt.Println(styles.Warning("No tags found"))
+			return nil
+		}
+
+		tags := make([]string, 0, len(counts))
+		for tag := range counts {
+			tags = append(tags, tag)
+		}
+		// Most used first
+		sort.Slice(tags, func(i, j int) bool {
+			if counts[tags[i]] != counts[tags[j]] {
+				return counts[tags[i]] > counts[tags[j]]
+			}
+			return tags[i] < tags[j]
+		})
+		for _, tag := range tags {
+			fmt.Printf("%4d  %s\n", counts[tag], styles.TagText("%s", tag))
+		}
+		return nil
+	},
+}
+
+// tagCounts counts the playgrounds with each tag
+func tagCounts(projects []*model.Project) map[string]int {
+	counts := make(map[string]int)
+	for _, p := range projects {
+		for _, tag := range project.NormalizeTags(p.Tags) {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// completeTags completes tag names in use across the workspace
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := openStore(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	projects, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	tags := make([]string, 0)
+	for tag := range tagCounts(projects) {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagAddCmd, tagRemoveCmd, tagRenameCmd, tagListCmd)
+
+	for _, c := range []*cobra.Command{tagAddCmd, tagRemoveCmd} {
+		c.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground (required)")
+		c.MarkFlagRequired("name")
+		c.RegisterFlagCompletionFunc("name", completeProjectNames)
+		c.ValidArgsFunction = completeTags
+	}
+	tagRenameCmd.ValidArgsFunction = completeTags
+}
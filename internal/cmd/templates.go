@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/crazywolf132/goshed/internal/styles"
 	tmpl "github.com/crazywolf132/goshed/internal/template"
@@ -11,14 +13,25 @@ import (
 var templatesCmd = &cobra.Command{
 	Use:   "templates",
 	Short: "List available templates",
-	Long: `List all available project templates.
+	Long: `List all available project templates: the built-in ones and those in
+~/.goshed/templates. A user template is a directory holding a template.yaml
+manifest (description and dependencies) next to the files new playgrounds
+start with; it replaces a built-in template of the same name.
 Example: goshed templates`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		templates := tmpl.List()
+		templates, err := tmpl.List()
+		if err != nil {
+			// The templates that did load are still listed
+			fmt.Println(styles.Warning("%v", err))
+		}
 
 		fmt.Printf("%s\n\n", styles.Title("Available Templates:"))
-		for name, t := range templates {
+		for _, name := range slices.Sorted(maps.Keys(templates)) {
+			t := templates[name]
 			fmt.Printf("%s %s\n", styles.ProjectName(name), styles.Header("- %s", t.Description))
+			if t.Path != "" {
+				fmt.Printf("  %s %s\n", styles.FieldName("From:"), styles.Path("%s", t.Path))
+			}
 			if len(t.Dependencies) > 0 {
 				fmt.Printf("  %s\n", styles.FieldName("Dependencies:"))
 				for _, dep := range t.Dependencies {
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/project"
+	"github.com/crazywolf132/goshed/internal/styles"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	trashOlderThan string
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage removed playgrounds",
+	Long: `Removed playgrounds are kept in the trash until they are older than the
+trash.retention setting (default 720h), so they can be restored. Expired
+entries are deleted by 'goshed clean'.
+Example: goshed trash list`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List playgrounds in the trash",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trash, err := storeFeature[project.Trasher]("the trash")
+		if err != nil {
+			return err
+		}
+		entries, err := trash.ListTrash()
+		if err != nil {
+			return fmt.Errorf("failed to list trash: %w", err)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println(styles.Warning("The trash is empty"))
+			return nil
+		}
+
+		fmt.Printf("%s\n\n", styles.Title("Found %d playgrounds in the trash:", len(entries)))
+		for _, entry := range entries {
+			fmt.Printf("%s %s\n", styles.FieldName("Name:"), styles.ProjectName(entry.Project.Name))
+			fmt.Printf("  %s %s\n", styles.FieldName("Removed:"), styles.TimeText(entry.RemovedAt.Format(time.RFC3339)))
+			if entry.Reason != "" {
+				fmt.Printf("  %s %s\n", styles.FieldName("Reason:"), entry.Reason)
+			}
+			fmt.Println()
+		}
+
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a playground from the trash",
+	Long: `Restore the most recently removed playground with the given name.
+Example: goshed trash restore -n myproject`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if projectName == "" {
+			return fmt.Errorf("project name is required")
+		}
+
+		trash, err := storeFeature[project.Trasher]("the trash")
+		if err != nil {
+			return err
+		}
+		p, err := trash.RestoreTrash(projectName)
+		if err != nil {
+			return fmt.Errorf("failed to restore project: %w", err)
+		}
+
+		fmt.Printf("%s %s\n", styles.Success("Restored"), styles.ProjectName(p.Name))
+		return nil
+	},
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete playgrounds in the trash",
+	Long: `Permanently delete playgrounds from the trash. Without --older-than the
+whole trash is emptied.
+Example: goshed trash empty --older-than 168h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var olderThan time.Duration
+		if trashOlderThan != "" {
+			d, err := project.ParseDuration(trashOlderThan)
+			if err != nil {
+				return err
+			}
+			olderThan = d
+		}
+
+		trash, err := storeFeature[project.Trasher]("the trash")
+		if err != nil {
+			return err
+		}
+
+		unlock, err := lockWorkspace()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		emptied, err := emptyTrash(trash, olderThan)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted %d projects\n", len(emptied))
+		return nil
+	},
+}
+
+// emptyTrash permanently deletes the trash entries removed more than
+// olderThan ago, printing each one. Callers hold the workspace lock.
+func emptyTrash(trash project.Trasher, olderThan time.Duration) ([]*project.TrashEntry, error) {
+	emptied, err := trash.EmptyTrash(olderThan)
+	for _, entry := range emptied {
+		fmt.Printf("Deleted %s from trash (removed: %s)\n", entry.Project.Name, entry.RemovedAt.Format(time.RFC3339))
+	}
+	if err != nil {
+		return emptied, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	return emptied, nil
+}
+
+// purgeExpiredTrash permanently deletes projects that have been in the
+// trash for longer than the configured trash.retention. A retention of 0
+// keeps them forever. Callers hold the workspace lock.
+func purgeExpiredTrash() error {
+	retention, err := project.ParseDuration(viper.GetString("trash.retention"))
+	if err != nil {
+		return fmt.Errorf("invalid trash.retention: %w", err)
+	}
+	trash, ok := store.(project.Trasher)
+	if retention <= 0 || !ok {
+		return nil
+	}
+	_, err = emptyTrash(trash, retention)
+	return err
+}
+
+// completeTrashNames completes a --name flag with the projects in the trash
+func completeTrashNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := openStore(); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	trash, ok := store.(project.Trasher)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, err := trash.ListTrash()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Project.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+
+	trashRestoreCmd.Flags().StringVarP(&projectName, "name", "n", "", "Name of the playground to restore (required)")
+	trashRestoreCmd.MarkFlagRequired("name")
+	trashRestoreCmd.RegisterFlagCompletionFunc("name", completeTrashNames)
+
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only delete playgrounds removed longer ago than this duration (e.g., 168h)")
+}
@@ -21,6 +21,11 @@ var workspaceCmd = &cobra.Command{
 	Short: "Manage workspaces",
 	Long: `Create and switch between workspaces.
 Example: goshed workspace create mywork`,
+	// Workspace management must work even when the current workspace is
+	// missing, so it does not open a project store.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
 }
 
 var workspaceCreateCmd = &cobra.Command{
@@ -31,7 +36,7 @@ var workspaceCreateCmd = &cobra.Command{
 			return fmt.Errorf("workspace name is required")
 		}
 
-		wsPath := filepath.Join(config.ConfigDir, "workspaces", workspaceName)
+		wsPath := config.WorkspaceDir(workspaceName)
 		if err := os.MkdirAll(wsPath, 0755); err != nil {
 			return fmt.Errorf("failed to create workspace: %w", err)
 		}
@@ -82,7 +87,7 @@ var workspaceSwitchCmd = &cobra.Command{
 			return fmt.Errorf("workspace name is required")
 		}
 
-		wsPath := filepath.Join(config.ConfigDir, "workspaces", workspaceName)
+		wsPath := config.WorkspaceDir(workspaceName)
 		if _, err := os.Stat(wsPath); os.IsNotExist(err) {
 			return fmt.Errorf("workspace %s does not exist", workspaceName)
 		}
@@ -34,7 +34,10 @@ func InitConfig() {
 
 	// Set defaults
 	viper.SetDefault("editor", "code")
+	viper.SetDefault("module_prefix", "")
 	viper.SetDefault("cleanup.older_than", "720h")
+	viper.SetDefault("cleanup.signal", "any")
+	viper.SetDefault("trash.retention", "720h")
 
 	// Read config
 	if err := viper.ReadInConfig(); err != nil {
@@ -47,9 +50,23 @@ func InitConfig() {
 
 // GetProjectsDir returns the projects directory for the current workspace
 func GetProjectsDir() string {
-	workspace := viper.GetString("workspace")
+	return WorkspaceDir(viper.GetString("workspace"))
+}
+
+// WorkspaceDir returns the projects directory for the named workspace.
+// An empty name refers to the default workspace.
+func WorkspaceDir(workspace string) string {
 	if workspace == "" {
 		return ProjectsDir
 	}
 	return filepath.Join(ConfigDir, "workspaces", workspace)
 }
+
+// ArchiveDir returns the directory holding the archived projects of the
+// named workspace. An empty name refers to the default workspace.
+func ArchiveDir(workspace string) string {
+	if workspace == "" {
+		return filepath.Join(ConfigDir, "archive")
+	}
+	return filepath.Join(ConfigDir, "archive", workspace)
+}
@@ -0,0 +1,253 @@
+// Package filter implements the query language used to select playgrounds,
+// e.g. "tag:web and template:api and accessed>30d and not pinned".
+//
+// Terms are combined with and, or and not, and grouped with parentheses.
+// Adjacent terms are joined by and. A word that is not a field or flag
+// matches playgrounds whose name contains it.
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+)
+
+// need records which expensive project state a query looks at, so callers
+// can bring it up to date before filtering
+type need int
+
+const (
+	needGit need = 1 << iota
+	needActivity
+	needSize
+)
+
+// Query is a parsed filter expression. It is safe for concurrent use.
+type Query struct {
+	root  node
+	needs need
+}
+
+// node is a part of a parsed query
+type node interface {
+	eval(p *model.Project, ctx *evalCtx) bool
+	// String renders the node back as query text
+	String() string
+}
+
+type andNode struct{ children []node }
+
+type orNode struct{ children []node }
+
+type notNode struct{ child node }
+
+// termNode is a single condition, e.g. tag:web
+type termNode struct {
+	src   string
+	desc  string
+	needs need
+	// bare is set for words that match the name rather than a field
+	bare  bool
+	match func(p *model.Project, ctx *evalCtx) bool
+}
+
+// evalCtx carries the state shared by the evaluation of one batch of
+// projects
+type evalCtx struct {
+	now time.Time
+
+	mu  sync.Mutex
+	git map[*model.Project]*project.GitStatus
+}
+
+func newEvalCtx(now time.Time) *evalCtx {
+	return &evalCtx{now: now, git: make(map[*model.Project]*project.GitStatus)}
+}
+
+// gitStatus returns a project's git status, reading it on first use. A nil
+// status means it could not be read.
+func (c *evalCtx) gitStatus(p *model.Project) *project.GitStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.git[p]
+	if !ok {
+		status, _ = project.GetGitStatus(p)
+		c.git[p] = status
+	}
+	return status
+}
+
+func (n *andNode) eval(p *model.Project, ctx *evalCtx) bool {
+	for _, c := range n.children {
+		if !c.eval(p, ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *orNode) eval(p *model.Project, ctx *evalCtx) bool {
+	for _, c := range n.children {
+		if c.eval(p, ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *notNode) eval(p *model.Project, ctx *evalCtx) bool {
+	return !n.child.eval(p, ctx)
+}
+
+func (n *termNode) eval(p *model.Project, ctx *evalCtx) bool {
+	return n.match(p, ctx)
+}
+
+func (n *andNode) String() string { return joinNodes(n.children, " and ") }
+
+func (n *orNode) String() string { return joinNodes(n.children, " or ") }
+
+func (n *notNode) String() string { return "not " + group(n.child) }
+
+func (n *termNode) String() string { return n.src }
+
+func joinNodes(nodes []node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, c := range nodes {
+		parts[i] = group(c)
+	}
+	return strings.Join(parts, sep)
+}
+
+// group parenthesises and and or nodes nested inside another node
+func group(n node) string {
+	switch n.(type) {
+	case *andNode, *orNode:
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// String renders the query in its canonical form
+func (q *Query) String() string {
+	return q.root.String()
+}
+
+// NeedsGit reports whether the query looks at git status
+func (q *Query) NeedsGit() bool { return q.needs&needGit != 0 }
+
+// NeedsActivity reports whether the query looks at file or commit
+// activity, which callers should refresh first
+func (q *Query) NeedsActivity() bool { return q.needs&needActivity != 0 }
+
+// NeedsSize reports whether the query looks at the cached size, which
+// callers should refresh first
+func (q *Query) NeedsSize() bool { return q.needs&needSize != 0 }
+
+// Structured reports whether the query uses any field, flag or operator,
+// rather than only words matched against names
+func (q *Query) Structured() bool {
+	t, ok := q.root.(*termNode)
+	if ok {
+		return !t.bare
+	}
+	if and, ok := q.root.(*andNode); ok {
+		for _, c := range and.children {
+			if t, ok := c.(*termNode); !ok || !t.bare {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Match reports whether a project satisfies the query
+func (q *Query) Match(p *model.Project, now time.Time) bool {
+	return q.root.eval(p, newEvalCtx(now))
+}
+
+// Filter returns the projects that satisfy the query, in order
+func (q *Query) Filter(projects []*model.Project, now time.Time) []*model.Project {
+	ctx := newEvalCtx(now)
+	if q.NeedsGit() {
+		for i, status := range project.GetGitStatuses(projects) {
+			ctx.git[projects[i]] = status
+		}
+	}
+
+	matched := make([]*model.Project, 0, len(projects))
+	for _, p := range projects {
+		if q.root.eval(p, ctx) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// Explain renders the query as a tree showing how it is evaluated
+func (q *Query) Explain() string {
+	var b strings.Builder
+	explain(&b, q.root, "", "")
+	return b.String()
+}
+
+func explain(b *strings.Builder, n node, first, rest string) {
+	var children []node
+	switch n := n.(type) {
+	case *andNode:
+		fmt.Fprintf(b, "%sall of:\n", first)
+		children = n.children
+	case *orNode:
+		fmt.Fprintf(b, "%sany of:\n", first)
+		children = n.children
+	case *notNode:
+		fmt.Fprintf(b, "%snot:\n", first)
+		children = []node{n.child}
+	case *termNode:
+		fmt.Fprintf(b, "%s%s  (%s)\n", first, n.src, n.desc)
+	}
+
+	for i, c := range children {
+		if i == len(children)-1 {
+			explain(b, c, rest+"└── ", rest+"    ")
+		} else {
+			explain(b, c, rest+"├── ", rest+"│   ")
+		}
+	}
+}
+
+// Reason explains why a project does not satisfy the query by naming the
+// terms that ruled it out. It returns an empty string for a match.
+func (q *Query) Reason(p *model.Project, now time.Time) string {
+	ctx := newEvalCtx(now)
+	if q.root.eval(p, ctx) {
+		return ""
+	}
+	return reason(q.root, p, ctx)
+}
+
+// reason explains why n is false for p
+func reason(n node, p *model.Project, ctx *evalCtx) string {
+	switch n := n.(type) {
+	case *andNode:
+		for _, c := range n.children {
+			if !c.eval(p, ctx) {
+				return reason(c, p, ctx)
+			}
+		}
+	case *orNode:
+		reasons := make([]string, len(n.children))
+		for i, c := range n.children {
+			reasons[i] = reason(c, p, ctx)
+		}
+		return strings.Join(reasons, "; ")
+	case *notNode:
+		return fmt.Sprintf("%s is true", n.child)
+	}
+	return fmt.Sprintf("%s is false", n)
+}
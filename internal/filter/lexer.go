@@ -0,0 +1,94 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+// token is a lexical token of a query. pos is the byte offset of the token
+// in the query.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// ParseError describes where and why a query failed to parse
+type ParseError struct {
+	Query string
+	// Pos is the byte offset of the problem in Query
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid filter at column %d: %s\n  %s\n  %s^",
+		e.Pos+1, e.Msg, e.Query, strings.Repeat(" ", e.Pos))
+}
+
+// isOpChar reports whether r starts a comparison operator
+func isOpChar(r rune) bool {
+	return r == ':' || r == '=' || r == '<' || r == '>'
+}
+
+// isWordChar reports whether r can appear in an unquoted word
+func isWordChar(r rune) bool {
+	return !unicode.IsSpace(r) && !isOpChar(r) && r != '(' && r != ')' && r != '"' && r != '\''
+}
+
+// lex splits a query into tokens, ending with tokEOF
+func lex(query string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(query) {
+		r, size := utf8.DecodeRuneInString(query[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			end := strings.IndexByte(query[i+1:], query[i])
+			if end < 0 {
+				return nil, &ParseError{Query: query, Pos: i, Msg: "unterminated quoted string"}
+			}
+			tokens = append(tokens, token{tokString, query[i+1 : i+1+end], i})
+			i += end + 2
+		case isOpChar(r):
+			op := query[i : i+1]
+			if (r == '<' || r == '>') && i+1 < len(query) && query[i+1] == '=' {
+				op = query[i : i+2]
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i += len(op)
+		default:
+			start := i
+			for i < len(query) {
+				r, size := utf8.DecodeRuneInString(query[i:])
+				if !isWordChar(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{tokWord, query[start:i], start})
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(query)}), nil
+}
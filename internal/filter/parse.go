@@ -0,0 +1,499 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+)
+
+// textFields match a glob against a project attribute, e.g. tag:web*
+var textFields = map[string]struct {
+	desc   string
+	values func(p *model.Project) []string
+}{
+	"tag":      {"has tag", func(p *model.Project) []string { return p.Tags }},
+	"template": {"template is", func(p *model.Project) []string { return []string{p.Template} }},
+	"name":     {"name is", func(p *model.Project) []string { return []string{p.Name} }},
+	"module":   {"module is", func(p *model.Project) []string { return []string{p.Module} }},
+}
+
+// timeFields compare how long ago something happened, e.g. accessed>30d
+var timeFields = map[string]struct {
+	desc string
+	need need
+	when func(p *model.Project) time.Time
+}{
+	"created":  {"created", 0, func(p *model.Project) time.Time { return p.Created }},
+	"accessed": {"last accessed", 0, func(p *model.Project) time.Time { return p.LastAccessed }},
+	"modified": {"last modified", needActivity, func(p *model.Project) time.Time {
+		t, _ := project.LastActivity(p, project.SignalModified)
+		return t
+	}},
+	"commit": {"last committed", needActivity, func(p *model.Project) time.Time {
+		t, _ := project.LastActivity(p, project.SignalCommit)
+		return t
+	}},
+	"activity": {"last active", needActivity, func(p *model.Project) time.Time {
+		t, _ := project.LastActivity(p, project.SignalAny)
+		return t
+	}},
+}
+
+// flags are terms on their own, e.g. pinned
+var flags = map[string]struct {
+	desc  string
+	match func(p *model.Project, ctx *evalCtx) bool
+}{
+	"pinned":   {"is pinned", func(p *model.Project, ctx *evalCtx) bool { return p.Pinned }},
+	"external": {"is registered by reference", func(p *model.Project, ctx *evalCtx) bool { return p.External }},
+	"expired":  {"has expired", func(p *model.Project, ctx *evalCtx) bool { return project.Expired(p, ctx.now) }},
+}
+
+var gitStates = map[string]struct {
+	desc  string
+	match func(s *project.GitStatus) bool
+}{
+	"dirty": {"has uncommitted changes", func(s *project.GitStatus) bool { return s.Initialized && !s.Clean }},
+	"clean": {"has no uncommitted changes", func(s *project.GitStatus) bool { return s.Initialized && s.Clean }},
+	"none":  {"is not a git repository", func(s *project.GitStatus) bool { return !s.Initialized }},
+}
+
+// fieldNames lists every field for error messages
+const fieldNames = "tag, template, name, module, prop, git, created, accessed, modified, commit, activity, expires, size"
+
+// Parse parses a query. Errors are *ParseError and point at the offending
+// part of the query.
+func Parse(query string) (*Query, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokEOF {
+		return nil, &ParseError{Query: query, Pos: 0, Msg: "empty filter"}
+	}
+
+	ps := &parser{query: query, tokens: tokens}
+	root, err := ps.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := ps.peek(); t.kind != tokEOF {
+		if t.kind == tokRParen {
+			return nil, ps.errorf(t.pos, "unexpected ) without a matching (")
+		}
+		return nil, ps.errorf(t.pos, "unexpected %q", t.text)
+	}
+
+	q := &Query{root: root}
+	q.needs = needsOf(root)
+	return q, nil
+}
+
+func needsOf(n node) need {
+	switch n := n.(type) {
+	case *andNode:
+		return needsOfAll(n.children)
+	case *orNode:
+		return needsOfAll(n.children)
+	case *notNode:
+		return needsOf(n.child)
+	case *termNode:
+		return n.needs
+	}
+	return 0
+}
+
+func needsOfAll(nodes []node) need {
+	var needs need
+	for _, c := range nodes {
+		needs |= needsOf(c)
+	}
+	return needs
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	i      int
+}
+
+func (ps *parser) peek() token {
+	return ps.tokens[ps.i]
+}
+
+func (ps *parser) next() token {
+	t := ps.tokens[ps.i]
+	if t.kind != tokEOF {
+		ps.i++
+	}
+	return t
+}
+
+func (ps *parser) errorf(pos int, format string, args ...any) error {
+	return &ParseError{Query: ps.query, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword reports whether t is the given keyword, in any case
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, keyword)
+}
+
+// parseOr parses terms joined by or
+func (ps *parser) parseOr() (node, error) {
+	first, err := ps.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for isKeyword(ps.peek(), "or") {
+		ps.next()
+		n, err := ps.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &orNode{children: children}, nil
+}
+
+// parseAnd parses terms joined by and, or simply written one after another
+func (ps *parser) parseAnd() (node, error) {
+	first, err := ps.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for {
+		t := ps.peek()
+		if t.kind == tokEOF || t.kind == tokRParen || isKeyword(t, "or") {
+			break
+		}
+		if isKeyword(t, "and") {
+			ps.next()
+		}
+		n, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &andNode{children: children}, nil
+}
+
+// parseUnary parses a term, a negation or a parenthesised group
+func (ps *parser) parseUnary() (node, error) {
+	t := ps.peek()
+	switch {
+	case t.kind == tokEOF:
+		return nil, ps.errorf(t.pos, "unexpected end of filter, expected a term")
+	case t.kind == tokRParen:
+		return nil, ps.errorf(t.pos, "unexpected ), expected a term")
+	case t.kind == tokOp:
+		return nil, ps.errorf(t.pos, "unexpected %q, expected a field name before it", t.text)
+	case isKeyword(t, "and"), isKeyword(t, "or"):
+		return nil, ps.errorf(t.pos, "expected a term before %q", t.text)
+	case isKeyword(t, "not"):
+		ps.next()
+		child, err := ps.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	case t.kind == tokLParen:
+		ps.next()
+		n, err := ps.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if ps.peek().kind != tokRParen {
+			return nil, ps.errorf(t.pos, "missing ) to close this (")
+		}
+		ps.next()
+		return n, nil
+	}
+	return ps.parseTerm()
+}
+
+// parseTerm parses field:value, field<op>value, a flag or a bare word
+func (ps *parser) parseTerm() (node, error) {
+	t := ps.next()
+	if t.kind == tokString {
+		return nameContains(t.text), nil
+	}
+	if ps.peek().kind != tokOp {
+		if f, ok := flags[strings.ToLower(t.text)]; ok {
+			return &termNode{src: strings.ToLower(t.text), desc: f.desc, match: f.match}, nil
+		}
+		return nameContains(t.text), nil
+	}
+
+	field := strings.ToLower(t.text)
+	op := ps.next()
+
+	if f, ok := textFields[field]; ok {
+		if err := ps.expectOp(field, op, ":"); err != nil {
+			return nil, err
+		}
+		value, err := ps.value(field, op)
+		if err != nil {
+			return nil, err
+		}
+		if field == "tag" {
+			// Tags are stored normalized
+			value.text = strings.ToLower(value.text)
+		}
+		if _, err := path.Match(value.text, ""); err != nil {
+			return nil, ps.errorf(value.pos, "invalid pattern %q", value.text)
+		}
+		return &termNode{
+			src:  field + ":" + quote(value.text),
+			desc: fmt.Sprintf("%s %q", f.desc, value.text),
+			match: func(p *model.Project, ctx *evalCtx) bool {
+				for _, v := range f.values(p) {
+					if ok, _ := path.Match(value.text, v); ok {
+						return true
+					}
+				}
+				return false
+			},
+		}, nil
+	}
+
+	if f, ok := timeFields[field]; ok {
+		d, text, err := ps.duration(field, op)
+		if err != nil {
+			return nil, err
+		}
+		return &termNode{
+			src:   field + op.text + text,
+			desc:  fmt.Sprintf("%s %s %s ago", f.desc, comparisonText(op.text), project.FormatDuration(d)),
+			needs: f.need,
+			match: func(p *model.Project, ctx *evalCtx) bool {
+				when := f.when(p)
+				if when.IsZero() {
+					// Never happened counts as longer ago than anything
+					return op.text == ">" || op.text == ">="
+				}
+				return compare(ctx.now.Sub(when), op.text, d)
+			},
+		}, nil
+	}
+
+	switch field {
+	case "prop":
+		return ps.propTerm(op)
+	case "git":
+		if err := ps.expectOp(field, op, ":"); err != nil {
+			return nil, err
+		}
+		value, err := ps.value(field, op)
+		if err != nil {
+			return nil, err
+		}
+		state, ok := gitStates[strings.ToLower(value.text)]
+		if !ok {
+			return nil, ps.errorf(value.pos, "unknown git state %q (valid: dirty, clean, none)", value.text)
+		}
+		return &termNode{
+			src:   "git:" + strings.ToLower(value.text),
+			desc:  "git repository " + state.desc,
+			needs: needGit,
+			match: func(p *model.Project, ctx *evalCtx) bool {
+				status := ctx.gitStatus(p)
+				return status != nil && state.match(status)
+			},
+		}, nil
+	case "expires":
+		d, text, err := ps.duration(field, op)
+		if err != nil {
+			return nil, err
+		}
+		return &termNode{
+			src:  field + op.text + text,
+			desc: fmt.Sprintf("has an expiry %s %s away", comparisonText(op.text), project.FormatDuration(d)),
+			match: func(p *model.Project, ctx *evalCtx) bool {
+				return p.ExpiresAt != nil && compare(p.ExpiresAt.Sub(ctx.now), op.text, d)
+			},
+		}, nil
+	case "size":
+		if op.text == ":" || op.text == "=" {
+			return nil, ps.errorf(op.pos, "size needs a comparison such as size>100MiB")
+		}
+		value, err := ps.value(field, op)
+		if err != nil {
+			return nil, err
+		}
+		n, err := parseSize(value.text)
+		if err != nil {
+			return nil, ps.errorf(value.pos, "%v", err)
+		}
+		return &termNode{
+			src:   field + op.text + value.text,
+			desc:  fmt.Sprintf("uses %s %s", comparisonText(op.text), project.FormatSize(n)),
+			needs: needSize,
+			match: func(p *model.Project, ctx *evalCtx) bool {
+				var size int64
+				if p.Size != nil {
+					size = p.Size.Bytes
+				}
+				return compare(size, op.text, n)
+			},
+		}, nil
+	}
+
+	if _, ok := flags[field]; ok {
+		return nil, ps.errorf(op.pos, "%s is a flag and takes no value; write just %q", field, field)
+	}
+	return nil, ps.errorf(t.pos, "unknown field %q (valid: %s)", t.text, fieldNames)
+}
+
+// propTerm parses the rest of prop:key or prop:key=value
+func (ps *parser) propTerm(op token) (node, error) {
+	if err := ps.expectOp("prop", op, ":"); err != nil {
+		return nil, err
+	}
+	key, err := ps.value("prop", op)
+	if err != nil {
+		return nil, err
+	}
+	if err := project.ValidatePropertyKey(key.text); err != nil {
+		return nil, ps.errorf(key.pos, "%v", err)
+	}
+
+	f := project.PropertyFilter{Key: key.text}
+	src := "prop:" + key.text
+	desc := fmt.Sprintf("has property %q", key.text)
+	if eq := ps.peek(); eq.kind == tokOp && eq.text == "=" {
+		ps.next()
+		value, err := ps.value("prop:"+key.text, eq)
+		if err != nil {
+			return nil, err
+		}
+		f.Value, f.HasValue = value.text, true
+		src += "=" + quote(value.text)
+		desc = fmt.Sprintf("has property %s=%q", key.text, value.text)
+	}
+
+	return &termNode{src: src, desc: desc, match: func(p *model.Project, ctx *evalCtx) bool {
+		return f.Match(p)
+	}}, nil
+}
+
+// expectOp checks that a field is followed by the operator it supports
+func (ps *parser) expectOp(field string, op token, want string) error {
+	if op.text != want {
+		return ps.errorf(op.pos, "%s takes %q, as in %s%sx", field, want, field, want)
+	}
+	return nil
+}
+
+// value reads the value after field and op
+func (ps *parser) value(field string, op token) (token, error) {
+	t := ps.peek()
+	if t.kind != tokWord && t.kind != tokString {
+		return t, ps.errorf(t.pos, "expected a value after %q", field+op.text)
+	}
+	return ps.next(), nil
+}
+
+// duration reads the duration compared against after field and op, e.g.
+// the 30d of accessed>30d, and returns it with its text
+func (ps *parser) duration(field string, op token) (time.Duration, string, error) {
+	if op.text == ":" || op.text == "=" {
+		return 0, "", ps.errorf(op.pos, "%s needs a comparison such as %s>30d or %s<2h", field, field, field)
+	}
+	value, err := ps.value(field, op)
+	if err != nil {
+		return 0, "", err
+	}
+	d, err := project.ParseDuration(value.text)
+	if err != nil {
+		return 0, "", ps.errorf(value.pos, "%v", err)
+	}
+	return d, value.text, nil
+}
+
+// nameContains matches projects whose name contains word
+func nameContains(word string) *termNode {
+	lower := strings.ToLower(word)
+	return &termNode{
+		src:  quote(word),
+		desc: fmt.Sprintf("name contains %q", word),
+		bare: true,
+		match: func(p *model.Project, ctx *evalCtx) bool {
+			return strings.Contains(strings.ToLower(p.Name), lower)
+		},
+	}
+}
+
+// quote quotes a value that would not survive being parsed again unquoted
+func quote(s string) string {
+	for _, r := range s {
+		if !isWordChar(r) {
+			return strconv.Quote(s)
+		}
+	}
+	if s == "" || strings.EqualFold(s, "and") || strings.EqualFold(s, "or") || strings.EqualFold(s, "not") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func compare[T int64 | time.Duration](a T, op string, b T) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	default:
+		return a <= b
+	}
+}
+
+func comparisonText(op string) string {
+	switch op {
+	case ">":
+		return "more than"
+	case ">=":
+		return "at least"
+	case "<":
+		return "less than"
+	default:
+		return "at most"
+	}
+}
+
+// sizeUnits are binary, to match project.FormatSize
+var sizeUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+}
+
+// parseSize parses a size such as 512K, 100MiB or 1.5GB
+func parseSize(s string) (int64, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	unit, ok := sizeUnits[strings.ToLower(s[i:])]
+	if err != nil || !ok {
+		return 0, fmt.Errorf("invalid size %q: use units such as 512K, 100MiB or 2G", s)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"api", "api"},
+		{`"my api"`, `"my api"`},
+		{"tag:Web", "tag:web"},
+		{"tag:web template:cli", "tag:web and template:cli"},
+		{"tag:web or tag:cli", "tag:web or tag:cli"},
+		{"not pinned", "not pinned"},
+		{"(tag:a or tag:b) and not pinned", "(tag:a or tag:b) and not pinned"},
+		{"accessed>30d", "accessed>30d"},
+		{"size>=100MiB", "size>=100MiB"},
+		{"git:Dirty", "git:dirty"},
+		{"prop:ticket=ABC-1", "prop:ticket=ABC-1"},
+		{"name:'a b*'", `name:"a b*"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.query, err)
+			}
+			if got := q.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.query, got, tt.want)
+			}
+			// The canonical form must parse to itself
+			again, err := Parse(q.String())
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", q.String(), err)
+			}
+			if again.String() != q.String() {
+				t.Errorf("Parse(%q).String() = %q, want it unchanged", q.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"", 0, "empty filter"},
+		{"   ", 0, "empty filter"},
+		{"tag:web)", 7, "unexpected ) without a matching ("},
+		{"(tag:web", 0, "missing ) to close this ("},
+		{"tag:web and", 11, "unexpected end of filter"},
+		{"or tag:web", 0, `expected a term before "or"`},
+		{":web", 0, "expected a field name"},
+		{"colour:red", 0, `unknown field "colour"`},
+		{"size:1", 4, "size needs a comparison"},
+		{"size>lots", 5, `invalid size "lots"`},
+		{"accessed:3d", 8, "accessed needs a comparison"},
+		{"accessed>soon", 9, `invalid duration "soon"`},
+		{"pinned:x", 6, "pinned is a flag and takes no value"},
+		{"tag>web", 3, `tag takes ":"`},
+		{"tag:[web", 4, `invalid pattern "[web"`},
+		{"git:lost", 4, `unknown git state "lost"`},
+		{`name:"web`, 5, "unterminated quoted string"},
+		{"tag:", 4, `expected a value after "tag:"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) error = %v, want a *ParseError", tt.query, err)
+			}
+			if perr.Pos != tt.pos {
+				t.Errorf("Parse(%q) error at %d, want %d", tt.query, perr.Pos, tt.pos)
+			}
+			if !strings.Contains(perr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.query, perr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	p := &model.Project{
+		Name:         "web-api",
+		Template:     "web",
+		Tags:         []string{"http", "demo"},
+		LastAccessed: now.Add(-40 * 24 * time.Hour),
+		Properties:   map[string]string{"ticket": "ABC-1"},
+		Pinned:       true,
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"API", true},
+		{"cli", false},
+		{"tag:ht*", true},
+		{"tag:db", false},
+		{"template:web and pinned", true},
+		{"not pinned", false},
+		{"tag:db or name:web-*", true},
+		{"accessed>30d", true},
+		{"accessed<30d", false},
+		{"prop:ticket", true},
+		{"prop:ticket=ABC-2", false},
+		{"expires<1d", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.query, err)
+			}
+			if got := q.Match(p, now); got != tt.want {
+				t.Errorf("Parse(%q).Match() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,85 @@
 package model
 
 import (
+	"io/fs"
 	"time"
 )
 
+// SchemaVersion is the version of the .goshed.json schema written by this
+// build of goshed. Bump it whenever a change to Project requires existing
+// metadata to be rewritten, and add a migration in internal/project.
+const SchemaVersion = 3
+
 type Project struct {
-	Name         string    `json:"name"`
-	Created      time.Time `json:"created"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Name          string    `json:"name"`
+	Created       time.Time `json:"created"`
+	// LastAccessed is when a goshed command last touched the project
 	LastAccessed time.Time `json:"lastAccessed"`
-	Template     string    `json:"template"`
-	Tags         []string  `json:"tags"`
-	Notes        string    `json:"notes"`
-	Path         string    `json:"-"`
+	// LastModified is the newest file mtime in the project, ignoring .git
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	// LastCommit is the time of the project's newest git commit
+	LastCommit *time.Time `json:"lastCommit,omitempty"`
+	Template   string     `json:"template"`
+	// Module is the module path declared in the project's go.mod
+	Module string `json:"module,omitempty"`
+	// GoVersion and Toolchain are the go and toolchain directives the
+	// project's go.mod was created with
+	GoVersion string   `json:"goVersion,omitempty"`
+	Toolchain string   `json:"toolchain,omitempty"`
+	Tags      []string `json:"tags"`
+	// Properties are free-form key/value pairs such as ticket=ABC-123
+	Properties map[string]string `json:"properties,omitempty"`
+	// Notes is the project's journal, oldest entry first. Longer markdown
+	// bodies live in NOTES.md next to the metadata.
+	Notes []Note `json:"notes"`
+	// ExpiresAt is when clean may remove the project, regardless of when it
+	// was last accessed
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Size caches the project's disk usage for sorting
+	Size *SizeCache `json:"size,omitempty"`
+	// Pinned projects are never cleaned up and sort first
+	Pinned bool `json:"pinned,omitempty"`
+	// ForkedFrom is the name of the project this one was forked from
+	ForkedFrom string `json:"forkedFrom,omitempty"`
+	// Scratch is set while a project created by goshed scratch has not been
+	// kept. Scratch projects are left out of List.
+	Scratch bool `json:"scratch,omitempty"`
+	// Revision is incremented on every save and lets the store detect
+	// writes based on stale metadata
+	Revision int    `json:"revision"`
+	Path     string `json:"-"`
+	// External is set for projects registered by reference, whose files
+	// live outside the workspace
+	External bool `json:"-"`
+}
+
+// SizeCache is a project's disk usage as of the time it was measured
+type SizeCache struct {
+	Bytes    int64     `json:"bytes"`
+	Computed time.Time `json:"computed"`
+}
+
+// Note is one timestamped entry in a project's journal
+type Note struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+	// Edited is set once the note has been changed after it was added
+	Edited *time.Time `json:"edited,omitempty"`
+	Text   string     `json:"text"`
+	// HasBody is set when NOTES.md holds a markdown body for the note
+	HasBody bool `json:"hasBody,omitempty"`
 }
 
 type Template struct {
-	Name         string
-	Description  string
-	Files        map[string]string
+	Name        string
+	Description string
+	Files       map[string]string
+	// Modes holds the permissions of the files that are not 0644, such as
+	// executable scripts
+	Modes        map[string]fs.FileMode
 	Dependencies []string
+	// Path is the directory a user template was loaded from; it is empty
+	// for built-in templates
+	Path string
 }
@@ -0,0 +1,181 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// Activity signals clean can judge a project's age by
+const (
+	SignalAccessed = "accessed"
+	SignalModified = "modified"
+	SignalCommit   = "commit"
+	SignalAny      = "any"
+)
+
+// Signals lists the valid activity signals
+var Signals = []string{SignalAccessed, SignalModified, SignalCommit, SignalAny}
+
+// goshedFiles are written by goshed itself and say nothing about whether
+// the project is in use
+var goshedFiles = map[string]bool{
+	".goshed.json":     true,
+	".goshed.json.bak": true,
+	refFile:            true,
+}
+
+// ValidateSignal checks that signal names a known activity signal
+func ValidateSignal(signal string) error {
+	for _, s := range Signals {
+		if s == signal {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown activity signal %q (valid: %s)", signal, strings.Join(Signals, ", "))
+}
+
+// LastActivity returns the time of a project's latest activity according
+// to the given signal. SignalAny takes the latest of all of them.
+func LastActivity(p *model.Project, signal string) (time.Time, error) {
+	switch signal {
+	case SignalAccessed:
+		return p.LastAccessed, nil
+	case SignalModified:
+		return timeOrZero(p.LastModified), nil
+	case SignalCommit:
+		return timeOrZero(p.LastCommit), nil
+	case SignalAny:
+		latest := p.LastAccessed
+		for _, t := range []*time.Time{p.LastModified, p.LastCommit} {
+			if t != nil && t.After(latest) {
+				latest = *t
+			}
+		}
+		return latest, nil
+	default:
+		return time.Time{}, ValidateSignal(signal)
+	}
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// RefreshActivity recomputes a project's newest file mtime and newest git
+// commit from disk. It reports whether either changed.
+func RefreshActivity(p *model.Project) (bool, error) {
+	if p.Path == "" {
+		return false, nil
+	}
+
+	modified, err := newestFile(p.Path)
+	if err != nil {
+		return false, err
+	}
+	commit := newestCommit(p.Path)
+
+	changed := !sameTime(p.LastModified, modified) || !sameTime(p.LastCommit, commit)
+	p.LastModified = modified
+	p.LastCommit = commit
+	return changed, nil
+}
+
+// RefreshActivities refreshes the activity of each project concurrently and
+// returns the projects whose activity changed
+func RefreshActivities(projects []*model.Project) []*model.Project {
+	changed := make([]bool, len(projects))
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func(i int, p *model.Project) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ok, err := RefreshActivity(p); err == nil {
+				changed[i] = ok
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	var result []*model.Project
+	for i, p := range projects {
+		if changed[i] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// newestFile returns the newest mtime of the files in dir, ignoring .git
+// and goshed's own metadata, or nil if there are none
+func newestFile(dir string) (*time.Time, error) {
+	var newest time.Time
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if goshedFiles[d.Name()] {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	if newest.IsZero() {
+		return nil, nil
+	}
+	return &newest, nil
+}
+
+// newestCommit returns the commit time of HEAD in dir, or nil if there is
+// no repository or no commit
+func newestCommit(dir string) *time.Time {
+	output, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%ct").Output()
+	if err != nil {
+		return nil
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(secs, 0)
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,322 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// Adopter is implemented by stores that can take in existing directories
+type Adopter interface {
+	// Adopt turns an existing directory into a project, either moving it
+	// into the store or registering it in place by reference
+	Adopt(dir string, p *model.Project, move bool) (*model.Project, error)
+}
+
+// refFile marks a workspace directory as the registration of a project
+// whose files live elsewhere
+const refFile = ".goshed-ref.json"
+
+// projectRef is the content of a refFile
+type projectRef struct {
+	Path string `json:"path"`
+}
+
+// resolveDir returns the directory holding a project's files and whether
+// it lives outside the workspace
+func (s *FSStore) resolveDir(name string) (string, bool, error) {
+	dir := s.projectDir(name)
+	data, err := os.ReadFile(filepath.Join(dir, refFile))
+	if os.IsNotExist(err) {
+		return dir, false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read project reference: %w", err)
+	}
+
+	var ref projectRef
+	if err := json.Unmarshal(data, &ref); err != nil || ref.Path == "" {
+		return "", false, fmt.Errorf("project %s has a corrupt reference file", name)
+	}
+	return ref.Path, true, nil
+}
+
+// templateImports maps an import path to the template it suggests, in
+// order of precedence
+var templateImports = []struct {
+	prefix   string
+	template string
+}{
+	{"github.com/99designs/gqlgen", "graphql"},
+	{"github.com/go-chi/chi", "api"},
+	{"github.com/spf13/cobra", "cli"},
+	{"net/http", "web"},
+}
+
+// InspectDir works out the metadata for adopting an existing directory. An
+// existing .goshed.json is used as is. Otherwise the module comes from
+// go.mod and the name is the slug of its last element, the template is
+// guessed from the imports and Created is taken from the earliest git
+// commit or file mtime.
+func InspectDir(dir string) (*model.Project, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, ".goshed.json")); err == nil {
+		p, _, err := decodeMetadata(data)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	files, err := readModuleFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	p := &model.Project{
+		Name:         Slugify(filepath.Base(dir)),
+		Module:       modulePath(files),
+		Template:     guessTemplate(files),
+		LastAccessed: time.Now(),
+		Tags:         []string{},
+	}
+	if p.Module != "" {
+		p.Name = Slugify(moduleName(p.Module))
+	}
+	if f, err := modfile.ParseLax("go.mod", files["go.mod"], nil); err == nil {
+		if f.Go != nil {
+			p.GoVersion = f.Go.Version
+		}
+		if f.Toolchain != nil {
+			p.Toolchain = f.Toolchain.Name
+		}
+	}
+
+	created, err := earliestCommit(dir)
+	if err != nil {
+		created, err = earliestModTime(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.Created = created
+
+	return p, nil
+}
+
+// moduleName returns the last element of a module path, ignoring a major
+// version suffix
+func moduleName(modPath string) string {
+	prefix, _, ok := module.SplitPathVersion(modPath)
+	if ok && prefix != "" {
+		modPath = prefix
+	}
+	return path.Base(modPath)
+}
+
+// guessTemplate picks the built-in template closest to the imports of the
+// given Go files
+func guessTemplate(files map[string][]byte) string {
+	imports := make(map[string]bool)
+	fset := token.NewFileSet()
+	for name, data := range files {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, data, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				imports[p] = true
+			}
+		}
+	}
+
+	for _, candidate := range templateImports {
+		for imp := range imports {
+			if imp == candidate.prefix || strings.HasPrefix(imp, candidate.prefix+"/") {
+				return candidate.template
+			}
+		}
+	}
+	return "basic"
+}
+
+// earliestCommit returns the time of the oldest root commit of the git
+// repository in dir
+func earliestCommit(dir string) (time.Time, error) {
+	output, err := exec.Command("git", "-C", dir, "log", "--max-parents=0", "--format=%ct", "HEAD").Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var earliest time.Time
+	for _, line := range strings.Fields(string(output)) {
+		secs, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			continue
+		}
+		if t := time.Unix(secs, 0); earliest.IsZero() || t.Before(earliest) {
+			earliest = t
+		}
+	}
+	if earliest.IsZero() {
+		return time.Time{}, fmt.Errorf("no commits in %s", dir)
+	}
+	return earliest, nil
+}
+
+// earliestModTime returns the oldest mtime of the files in dir, ignoring
+// .git
+func earliestModTime(dir string) (time.Time, error) {
+	var earliest time.Time
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if earliest.IsZero() || info.ModTime().Before(earliest) {
+			earliest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if earliest.IsZero() {
+		earliest = time.Now()
+	}
+	return earliest, nil
+}
+
+// Adopt turns an existing directory into a project. With move set the
+// directory is moved into the workspace; otherwise it stays where it is and
+// the workspace only holds a reference to it.
+func (s *FSStore) Adopt(dir string, p *model.Project, move bool) (*model.Project, error) {
+	if err := ValidateName(p.Name); err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if isWithin(s.root, dir) {
+		return nil, fmt.Errorf("%s is already inside the workspace", dir)
+	}
+	if isWithin(dir, s.root) {
+		return nil, fmt.Errorf("%s contains the workspace", dir)
+	}
+
+	unlock, err := s.lockProject(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	projectDir := s.projectDir(p.Name)
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s already exists", p.Name)
+	}
+
+	if move {
+		if err := moveDir(dir, projectDir); err != nil {
+			return nil, err
+		}
+		p.Path = projectDir
+		p.External = false
+	} else {
+		if err := os.MkdirAll(projectDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create project directory: %w", err)
+		}
+		data, err := json.MarshalIndent(projectRef{Path: dir}, "", "  ")
+		if err != nil {
+			os.RemoveAll(projectDir)
+			return nil, fmt.Errorf("failed to marshal project reference: %w", err)
+		}
+		if err := writeFileAtomic(filepath.Join(projectDir, refFile), data, 0644); err != nil {
+			os.RemoveAll(projectDir)
+			return nil, fmt.Errorf("failed to write project reference: %w", err)
+		}
+		p.Path = dir
+		p.External = true
+	}
+
+	if err := writeMetadata(p); err != nil {
+		if move {
+			moveDir(projectDir, dir)
+		} else {
+			os.RemoveAll(projectDir)
+		}
+		return nil, err
+	}
+
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+
+	return p, nil
+}
+
+// isWithin reports whether path is parent or lies inside it
+func isWithin(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// moveDir moves a directory, copying it when src and dst are on different
+// devices
+func moveDir(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move %s: %w", src, err)
+	}
+
+	if err := os.CopyFS(dst, os.DirFS(src)); err != nil {
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it: %w", src, err)
+	}
+	return nil
+}
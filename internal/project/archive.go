@@ -0,0 +1,347 @@
+package project
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// Archiver is implemented by stores that can pack projects away
+type Archiver interface {
+	// Archive packs a project into an archive and removes it from the
+	// active projects. Its metadata remains available from ListArchived.
+	Archive(name string) error
+	// Unarchive restores an archived project exactly as it was archived
+	Unarchive(name string) (*model.Project, error)
+	// ListArchived returns the metadata of every archived project
+	ListArchived() ([]*ArchivedProject, error)
+}
+
+// ArchivedProject describes a project packed into an archive. Its metadata
+// stays readable without unpacking the archive.
+type ArchivedProject struct {
+	Project    *model.Project `json:"project"`
+	ArchivedAt time.Time      `json:"archivedAt"`
+}
+
+// UnmarshalJSON decodes an archive sidecar, upgrading the project metadata
+// in it to the current schema
+func (a *ArchivedProject) UnmarshalJSON(data []byte) error {
+	type plain ArchivedProject
+	var raw struct {
+		plain
+		Project json.RawMessage `json:"project"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p, err := decodeEmbedded(raw.Project)
+	if err != nil {
+		return err
+	}
+	*a = ArchivedProject(raw.plain)
+	a.Project = p
+	return nil
+}
+
+func (s *FSStore) archivePath(name string) string {
+	return filepath.Join(s.archiveDir, name+".tar.gz")
+}
+
+func (s *FSStore) archiveMetadataPath(name string) string {
+	return filepath.Join(s.archiveDir, name+".json")
+}
+
+// Archive packs a project, including its metadata and Git history, into a
+// compressed archive and removes it from the workspace
+func (s *FSStore) Archive(name string) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	if p.External {
+		// Archiving would delete a directory goshed does not own
+		return fmt.Errorf("project %s is registered by reference to %s, whose files cannot be archived", name, p.Path)
+	}
+
+	if err := os.MkdirAll(s.archiveDir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	if _, err := os.Stat(s.archivePath(name)); !os.IsNotExist(err) {
+		return fmt.Errorf("an archive of %s already exists", name)
+	}
+
+	// Write the archive under a temporary name so a failure never leaves a
+	// truncated archive behind
+	tmp, err := os.CreateTemp(s.archiveDir, "."+name+".tar.gz.tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if err := writeTarGz(tmp, p.Path, nil); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+
+	data, err := json.MarshalIndent(ArchivedProject{Project: p, ArchivedAt: time.Now()}, "", "  ")
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to marshal archive metadata: %w", err)
+	}
+	if err := writeFileAtomic(s.archiveMetadataPath(name), data, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write archive metadata: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.archivePath(name)); err != nil {
+		os.Remove(tmpPath)
+		os.Remove(s.archiveMetadataPath(name))
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := os.RemoveAll(p.Path); err != nil {
+		return fmt.Errorf("archived %s but failed to remove it from the workspace: %w", name, err)
+	}
+
+	s.updateIndex(func(idx *index) error {
+		delete(idx.Entries, name)
+		return nil
+	})
+	return nil
+}
+
+// Unarchive unpacks an archived project back into the workspace exactly as
+// it was archived
+func (s *FSStore) Unarchive(name string) (*model.Project, error) {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	projectDir := s.projectDir(name)
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+
+	f, err := os.Open(s.archivePath(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no archive of %s found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	// Unpack next to the final location, hidden from the index, then move
+	// it into place in one step
+	tmpDir, err := os.MkdirTemp(s.root, ".restore-"+name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restore directory: %w", err)
+	}
+	if err := extractTarGz(f, tmpDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to unpack archive: %w", err)
+	}
+	if err := os.Rename(tmpDir, projectDir); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to restore project: %w", err)
+	}
+
+	f.Close()
+	os.Remove(s.archivePath(name))
+	os.Remove(s.archiveMetadataPath(name))
+
+	p, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+	return p, nil
+}
+
+// ListArchived returns the metadata of every archived project, sorted by
+// name
+func (s *FSStore) ListArchived() ([]*ArchivedProject, error) {
+	entries, err := os.ReadDir(s.archiveDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	var archived []*ArchivedProject
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.archiveDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive metadata: %w", err)
+		}
+		var a ArchivedProject
+		if err := json.Unmarshal(data, &a); err != nil || a.Project == nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read archive metadata %s\n", entry.Name())
+			continue
+		}
+		archived = append(archived, &a)
+	}
+
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Project.Name < archived[j].Project.Name
+	})
+	return archived, nil
+}
+
+// writeTarGz writes the contents of dir to w as a gzip-compressed tarball,
+// leaving out the paths, relative to dir, for which skip returns true. skip
+// may be nil.
+func writeTarGz(w io.Writer, dir string, skip func(rel string) bool) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// The root is stored as "./" so its permissions survive a restore
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if skip != nil && rel != "." && skip(filepath.ToSlash(rel)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// extractTarGz unpacks a gzip-compressed tarball into dir. Entries that
+// would escape dir are rejected.
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var dirs []*tar.Header
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %s escapes the project directory", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, hdr)
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+		}
+	}
+
+	// Restore directory permissions and times last, since writing their
+	// contents changes them
+	for _, hdr := range dirs {
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		os.Chmod(target, hdr.FileInfo().Mode().Perm())
+		os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+	}
+	return nil
+}
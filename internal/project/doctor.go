@@ -0,0 +1,292 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"golang.org/x/mod/modfile"
+)
+
+const quarantineDir = "quarantine"
+
+// Doctor is implemented by stores that can check their own consistency
+type Doctor interface {
+	// Diagnose inspects every project directory and the store's own state
+	// and returns the problems found
+	Diagnose() ([]*Issue, error)
+}
+
+// Issue is a problem found by Diagnose
+type Issue struct {
+	// Project is the directory the issue was found in, or empty for
+	// workspace-wide issues
+	Project string
+	Problem string
+	// Fix describes what Repair does, or is empty if the issue has to be
+	// fixed by hand
+	Fix    string
+	repair func() error
+}
+
+// Repair applies the issue's fix
+func (i *Issue) Repair() error {
+	if i.repair == nil {
+		return fmt.Errorf("no automatic fix available")
+	}
+	return i.repair()
+}
+
+// Diagnose checks every directory in the workspace for missing or corrupt
+// metadata, broken references, name and module mismatches and broken git
+// repositories, and looks for stale locks
+func (s *FSStore) Diagnose() ([]*Issue, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects directory: %w", err)
+	}
+
+	var issues []*Issue
+	for _, entry := range entries {
+		if isProjectDir(entry) {
+			issues = append(issues, s.diagnoseProject(entry.Name())...)
+		}
+	}
+	issues = append(issues, s.diagnoseLocks()...)
+
+	return issues, nil
+}
+
+// abandonedScratch is how long a scratch project may go untouched before
+// Diagnose assumes its session ended without keeping or discarding it
+const abandonedScratch = 24 * time.Hour
+
+func (s *FSStore) diagnoseProject(name string) []*Issue {
+	issue := func(problem, fix string, repair func() error) *Issue {
+		return &Issue{Project: name, Problem: problem, Fix: fix, repair: repair}
+	}
+
+	dir, external, err := s.resolveDir(name)
+	if err != nil {
+		return []*Issue{issue(err.Error(), "quarantine the directory", func() error {
+			return s.quarantine(name)
+		})}
+	}
+	if _, err := os.Stat(dir); external && err != nil {
+		return []*Issue{issue(fmt.Sprintf("refers to %s, which no longer exists", dir), "move the registration to the trash", func() error {
+			return s.Remove(name, fmt.Sprintf("doctor: %s no longer exists", dir))
+		})}
+	}
+
+	p, _, err := s.readMetadata(name, dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if !hasGoCode(dir) {
+			return []*Issue{issue("orphan directory with no metadata and no Go code", "quarantine the directory", func() error {
+				return s.quarantine(name)
+			})}
+		}
+		return []*Issue{issue("orphan directory with no metadata", "regenerate the metadata", func() error {
+			return s.regenerateMetadata(name, dir)
+		})}
+	case errors.Is(err, ErrSchemaTooNew):
+		return []*Issue{issue(err.Error(), "", nil)}
+	case err != nil:
+		return []*Issue{issue(fmt.Sprintf("corrupt metadata: %v", err), "back it up and regenerate the metadata", func() error {
+			if err := os.Rename(filepath.Join(dir, ".goshed.json"), filepath.Join(dir, ".goshed.json.corrupt")); err != nil {
+				return fmt.Errorf("failed to back up corrupt metadata: %w", err)
+			}
+			return s.regenerateMetadata(name, dir)
+		})}
+	}
+	p.External = external
+
+	var issues []*Issue
+	if p.Name != name {
+		issues = append(issues, issue(fmt.Sprintf("metadata names the project %q", p.Name), "rename it to match its directory", func() error {
+			return s.repairMetadata(name, func(p *model.Project) { p.Name = name })
+		}))
+	}
+
+	if p.Scratch && time.Since(p.LastAccessed) > abandonedScratch {
+		issues = append(issues, issue("scratch playground was never kept or discarded", "move it to the trash", func() error {
+			return s.Remove(name, "scratch: abandoned")
+		}))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	switch {
+	case os.IsNotExist(err):
+		module := p.Module
+		if module == "" {
+			module = name
+		}
+		if external {
+			// An adopted directory belongs to the user; goshed does not
+			// write files into it
+			issues = append(issues, issue("go.mod is missing", "", nil))
+			break
+		}
+		issues = append(issues, issue("go.mod is missing", fmt.Sprintf("write a go.mod for module %s", module), func() error {
+			goMod, err := goModFile(module, p.GoVersion, p.Toolchain)
+			if err != nil {
+				return err
+			}
+			if err := writeFileAtomic(filepath.Join(dir, "go.mod"), goMod, 0644); err != nil {
+				return fmt.Errorf("failed to write go.mod: %w", err)
+			}
+			return s.repairMetadata(name, func(p *model.Project) { p.Module = module })
+		}))
+	case err != nil:
+		issues = append(issues, issue(fmt.Sprintf("go.mod is unreadable: %v", err), "", nil))
+	default:
+		f, err := modfile.ParseLax("go.mod", data, nil)
+		if err != nil || f.Module == nil {
+			issues = append(issues, issue("go.mod is invalid or declares no module", "", nil))
+			break
+		}
+		if module := f.Module.Mod.Path; module != p.Module {
+			issues = append(issues, issue(fmt.Sprintf("metadata records module %q but go.mod declares %q", p.Module, module), "record the module from go.mod", func() error {
+				return s.repairMetadata(name, func(p *model.Project) { p.Module = module })
+			}))
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if problem, broken := diagnoseGit(dir); problem != "" {
+			if !broken || external {
+				issues = append(issues, issue(problem, "", nil))
+			} else {
+				issues = append(issues, issue(problem, "move .git aside and re-initialize git", func() error {
+					moved := filepath.Join(dir, fmt.Sprintf(".git.broken-%d", time.Now().Unix()))
+					if err := os.Rename(filepath.Join(dir, ".git"), moved); err != nil {
+						return fmt.Errorf("failed to move .git aside: %w", err)
+					}
+					return InitGit(&model.Project{Name: name, Path: dir})
+				}))
+			}
+		}
+	}
+
+	return issues
+}
+
+// diagnoseGit checks the git repository in dir. It returns the problem
+// found, if any, and whether the repository itself is unusable, as opposed
+// to git refusing to use it, such as for a directory owned by another user
+// that is not listed in safe.directory.
+func diagnoseGit(dir string) (string, bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-dir").CombinedOutput()
+	var exitErr *exec.ExitError
+	if err == nil || !errors.As(err, &exitErr) {
+		// Without a working git there is nothing to judge the repository by
+		return "", false
+	}
+
+	msg := strings.TrimSpace(string(out))
+	if strings.Contains(msg, "dubious ownership") || strings.Contains(msg, "safe.directory") {
+		return fmt.Sprintf("git refuses to use the repository: %s", msg), false
+	}
+	return fmt.Sprintf("broken git repository: %s", msg), true
+}
+
+// diagnoseLocks finds lock files that still name a process although
+// nobody holds the lock, left behind by processes that crashed
+func (s *FSStore) diagnoseLocks() []*Issue {
+	paths, _ := filepath.Glob(filepath.Join(s.root, stateDir, lockDir, "*.lock"))
+	paths = append(paths, s.workspaceLockPath())
+
+	var issues []*Issue
+	for _, path := range paths {
+		holder, stale, err := staleLock(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		var problem string
+		switch {
+		case err != nil:
+			problem = fmt.Sprintf("unreadable lock %s", filepath.Base(path))
+		case stale:
+			problem = fmt.Sprintf("stale lock %s held by exited process %d (%s)", filepath.Base(path), holder.PID, holder.Command)
+		default:
+			continue
+		}
+
+		issues = append(issues, &Issue{Problem: problem, Fix: "clear the lock", repair: func() error {
+			return clearLock(path)
+		}})
+	}
+	return issues
+}
+
+// regenerateMetadata writes fresh metadata for the project directory name,
+// inferred from its contents as adopt does
+func (s *FSStore) regenerateMetadata(name, dir string) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := InspectDir(dir)
+	if err != nil {
+		return err
+	}
+	p.Name = name
+	p.Path = dir
+	return writeMetadata(p)
+}
+
+// repairMetadata applies a change to a project's metadata without the
+// consistency checks of Get and Update, which the damage may defeat
+func (s *FSStore) repairMetadata(name string, apply func(p *model.Project)) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dir, _, err := s.resolveDir(name)
+	if err != nil {
+		return err
+	}
+	p, _, err := s.readMetadata(name, dir)
+	if err != nil {
+		return err
+	}
+
+	apply(p)
+	p.Revision++
+	return writeMetadata(p)
+}
+
+// quarantine moves a project directory out of the workspace into
+// .goshed/quarantine, where it can be inspected by hand
+func (s *FSStore) quarantine(name string) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	dest := filepath.Join(s.root, stateDir, quarantineDir, fmt.Sprintf("%s-%d", name, time.Now().UnixNano()))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := os.Rename(s.projectDir(name), dest); err != nil {
+		return fmt.Errorf("failed to quarantine %s: %w", name, err)
+	}
+	return nil
+}
+
+// hasGoCode reports whether dir holds a go.mod or any Go source file
+func hasGoCode(dir string) bool {
+	files, err := readModuleFiles(dir)
+	return err == nil && len(files) > 0
+}
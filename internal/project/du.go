@@ -0,0 +1,149 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// sizeCacheTTL is how long a cached size is trusted when nothing suggests
+// the project has changed
+const sizeCacheTTL = 24 * time.Hour
+
+// DiskUsage is the size in bytes of a project's files, split by kind
+type DiskUsage struct {
+	Source int64
+	Git    int64
+	Build  int64
+	Other  int64
+}
+
+// Total returns the combined size of all files
+func (u DiskUsage) Total() int64 {
+	return u.Source + u.Git + u.Build + u.Other
+}
+
+// Add adds another project's usage to u
+func (u *DiskUsage) Add(o DiskUsage) {
+	u.Source += o.Source
+	u.Git += o.Git
+	u.Build += o.Build
+	u.Other += o.Other
+}
+
+var (
+	sourceExts  = map[string]bool{".go": true, ".s": true, ".c": true, ".h": true, ".proto": true}
+	sourceFiles = map[string]bool{"go.mod": true, "go.sum": true, "go.work": true, "go.work.sum": true}
+	buildExts   = map[string]bool{".exe": true, ".test": true, ".a": true, ".so": true, ".dll": true, ".dylib": true, ".wasm": true}
+	buildDirs   = map[string]bool{"bin": true, "dist": true, "build": true}
+)
+
+// MeasureDiskUsage walks a project directory and sizes its files. Go
+// source and module files count as source; binaries and files under bin,
+// dist or build as build outputs.
+func MeasureDiskUsage(dir string) (DiskUsage, error) {
+	var u DiskUsage
+	if dir == "" {
+		return u, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		size := info.Size()
+		top, _, nested := strings.Cut(filepath.ToSlash(rel), "/")
+		ext := filepath.Ext(d.Name())
+		switch {
+		case nested && top == ".git":
+			u.Git += size
+		case sourceExts[ext] || sourceFiles[d.Name()]:
+			u.Source += size
+		case nested && buildDirs[top], buildExts[ext], info.Mode()&0111 != 0:
+			u.Build += size
+		default:
+			u.Other += size
+		}
+		return nil
+	})
+	if err != nil {
+		return u, fmt.Errorf("failed to measure %s: %w", dir, err)
+	}
+	return u, nil
+}
+
+// MeasureDiskUsages measures each project concurrently, in order. A nil
+// entry means the project could not be measured.
+func MeasureDiskUsages(projects []*model.Project) []*DiskUsage {
+	usages := make([]*DiskUsage, len(projects))
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func(i int, p *model.Project) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if u, err := MeasureDiskUsage(p.Path); err == nil {
+				usages[i] = &u
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	return usages
+}
+
+// SizeStale reports whether a project's cached size needs to be measured
+// again: it is missing, older than the project's last known activity, or
+// simply old
+func SizeStale(p *model.Project, now time.Time) bool {
+	if p.Size == nil {
+		return true
+	}
+	computed := p.Size.Computed
+	if computed.Before(p.LastAccessed) || (p.LastModified != nil && computed.Before(*p.LastModified)) {
+		return true
+	}
+	return now.Sub(computed) > sizeCacheTTL
+}
+
+// SetSize records a measured size in a project's metadata
+func SetSize(p *model.Project, u DiskUsage, now time.Time) {
+	p.Size = &model.SizeCache{Bytes: u.Total(), Computed: now}
+}
+
+// FormatSize renders a byte count with a binary unit, e.g. "1.5 MiB"
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
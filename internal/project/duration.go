@@ -0,0 +1,68 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// longUnits matches the day and week units that time.ParseDuration lacks
+var longUnits = regexp.MustCompile(`(\d+(?:\.\d+)?)([dw])`)
+
+// ParseDuration parses a duration like time.ParseDuration, but also
+// accepts days ("7d") and weeks ("2w"), alone or combined with shorter
+// units ("1d12h").
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	var long time.Duration
+	rest := longUnits.ReplaceAllStringFunc(s, func(m string) string {
+		parts := longUnits.FindStringSubmatch(m)
+		n, _ := strconv.ParseFloat(parts[1], 64)
+		unit := 24 * time.Hour
+		if parts[2] == "w" {
+			unit *= 7
+		}
+		long += time.Duration(n * float64(unit))
+		return ""
+	})
+
+	if rest == "" && s != "" {
+		return long, nil
+	}
+	d, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: use units such as 30m, 48h, 7d or 2w", s)
+	}
+	return long + d, nil
+}
+
+// FormatDuration renders a duration in days, hours and minutes, keeping
+// only the two largest units, e.g. "2d 3h" or "45m"
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	if d < time.Minute {
+		return "<1m"
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,27 @@
+package project
+
+import (
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// Expired reports whether a project's expiry time has passed
+func Expired(p *model.Project, now time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(now)
+}
+
+// ExpiringWithin returns the unpinned projects that have expired or will
+// expire within d of now
+func ExpiringWithin(projects []*model.Project, now time.Time, d time.Duration) []*model.Project {
+	var expiring []*model.Project
+	for _, p := range projects {
+		if p.Pinned || p.ExpiresAt == nil {
+			continue
+		}
+		if p.ExpiresAt.Before(now.Add(d)) {
+			expiring = append(expiring, p)
+		}
+	}
+	return expiring
+}
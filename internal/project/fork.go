@@ -0,0 +1,78 @@
+package project
+
+import (
+	"maps"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// forkMetadata returns the metadata for a new project forked from src. The
+// fork starts with fresh timestamps and the source's Go version, tags and
+// properties, and only keeps its notes when keepNotes is set. An empty
+// module keeps the source's.
+func forkMetadata(src *model.Project, name string, keepNotes bool, module string) *model.Project {
+	if module == "" {
+		module = src.Module
+	}
+
+	now := time.Now()
+	fork := &model.Project{
+		Name:         name,
+		Created:      now,
+		LastAccessed: now,
+		Template:     src.Template,
+		Module:       module,
+		GoVersion:    src.GoVersion,
+		Toolchain:    src.Toolchain,
+		Tags:         append([]string(nil), src.Tags...),
+		Properties:   maps.Clone(src.Properties),
+		ForkedFrom:   src.Name,
+	}
+	if keepNotes {
+		fork.Notes = append([]model.Note(nil), src.Notes...)
+	}
+	return fork
+}
+
+// moduleRenameChanges returns the new module path and the file changes
+// needed to move a module from a path generated from oldName to one
+// generated from newName. It returns no changes and an empty path if the
+// module path was not generated from the project name.
+func moduleRenameChanges(files map[string][]byte, oldName, newName string) (string, map[string][]byte, error) {
+	oldMod := modulePath(files)
+	newMod, ok := renamedModulePath(oldMod, oldName, newName)
+	if !ok {
+		return "", nil, nil
+	}
+	changed, err := rewriteModule(files, oldMod, newMod)
+	if err != nil {
+		return "", nil, err
+	}
+	return newMod, changed, nil
+}
+
+// Lineage returns the names of the projects p was forked from, nearest
+// first. It stops at an ancestor that no longer exists, which is still
+// included, and guards against cycles.
+func Lineage(p *model.Project, projects []*model.Project) []string {
+	byName := make(map[string]*model.Project, len(projects))
+	for _, other := range projects {
+		byName[other.Name] = other
+	}
+
+	var lineage []string
+	seen := map[string]bool{p.Name: true}
+	for parent := p.ForkedFrom; parent != "" && !seen[parent]; {
+		lineage = append(lineage, parent)
+		seen[parent] = true
+
+		ancestor, ok := byName[parent]
+		if !ok {
+			break
+		}
+		parent = ancestor.ForkedFrom
+	}
+
+	return lineage
+}
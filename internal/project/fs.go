@@ -0,0 +1,563 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/styles"
+)
+
+// FSStore is a Store that keeps each project in its own directory under a
+// workspace root, with metadata in a .goshed.json file
+type FSStore struct {
+	root       string
+	archiveDir string
+}
+
+// NewFSStore returns an FSStore bound to the given workspace root. Archived
+// projects are kept in archiveDir.
+func NewFSStore(root, archiveDir string) *FSStore {
+	return &FSStore{root: root, archiveDir: archiveDir}
+}
+
+// Root returns the workspace directory the store is bound to
+func (s *FSStore) Root() string {
+	return s.root
+}
+
+func (s *FSStore) projectDir(name string) string {
+	return filepath.Join(s.root, name)
+}
+
+// Create creates a new project with the given configuration
+func (s *FSStore) Create(p *model.Project) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	projectDir := s.projectDir(p.Name)
+
+	unlock, err := s.lockProject(p.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Check if project already exists
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		return fmt.Errorf("project %s already exists", p.Name)
+	}
+
+	if err := setModuleDefaults(p); err != nil {
+		return err
+	}
+
+	// Generate go.mod and the template files first, so an invalid module
+	// path leaves nothing behind
+	files, modes, err := projectFiles(p)
+	if err != nil {
+		return err
+	}
+
+	// Create project directory
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+	p.Path = projectDir
+
+	// Create metadata file
+	if err := writeMetadata(p); err != nil {
+		return err
+	}
+
+	// Write go.mod and the template files
+	for filename, content := range files {
+		filePath := filepath.Join(projectDir, filepath.FromSlash(filename))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+		}
+		perm, ok := modes[filename]
+		if !ok {
+			perm = 0644
+		}
+		if err := os.WriteFile(filePath, content, perm); err != nil {
+			return fmt.Errorf("failed to create file %s: %w", filename, err)
+		}
+	}
+
+	// Initialize Git repository
+	if err := InitGit(p); err != nil {
+		fmt.Printf("%s: %v\n", styles.Warning("Warning: Failed to initialize Git"), err)
+	}
+
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+
+	return nil
+}
+
+// Get retrieves a project by name. Metadata written with an older schema is
+// upgraded and saved back, keeping the original as .goshed.json.bak.
+func (s *FSStore) Get(name string) (*model.Project, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	projectDir := s.projectDir(name)
+
+	// Check if project exists
+	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+
+	dir, external, err := s.resolveDir(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); external && os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s refers to %s, which no longer exists", name, dir)
+	}
+
+	p, result, err := s.readMetadata(name, dir)
+	if err != nil {
+		return nil, err
+	}
+	p.External = external
+
+	if len(result.Applied) > 0 {
+		// Another process holding the lock will save the upgrade itself
+		var lockErr *LockError
+		if err := s.saveMigrated(name); err != nil && !errors.As(err, &lockErr) {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// readMetadata reads and upgrades the metadata in a project directory
+// without writing anything back
+func (s *FSStore) readMetadata(name, projectDir string) (*model.Project, *MigrationResult, error) {
+	metadataPath := filepath.Join(projectDir, ".goshed.json")
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read project metadata: %w", err)
+	}
+
+	p, result, err := decodeMetadata(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Set path
+	p.Path = projectDir
+	result.Name = name
+
+	return p, result, nil
+}
+
+// saveMigrated upgrades a project's metadata on disk, keeping the original
+// as .goshed.json.bak
+func (s *FSStore) saveMigrated(name string) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Re-read under the lock in case another process saved in the meantime
+	projectDir, _, err := s.resolveDir(name)
+	if err != nil {
+		return err
+	}
+	p, result, err := s.readMetadata(name, projectDir)
+	if err != nil {
+		return err
+	}
+	if len(result.Applied) == 0 {
+		return nil
+	}
+
+	metadataPath := filepath.Join(projectDir, ".goshed.json")
+	original, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return fmt.Errorf("failed to read project metadata: %w", err)
+	}
+	if err := writeFileAtomic(metadataPath+".bak", original, 0644); err != nil {
+		return fmt.Errorf("failed to back up project metadata: %w", err)
+	}
+
+	return writeMetadata(p)
+}
+
+// Migrate upgrades the metadata of every project in the workspace
+func (s *FSStore) Migrate(dryRun bool) ([]MigrationResult, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects directory: %w", err)
+	}
+
+	var results []MigrationResult
+	for _, entry := range entries {
+		if !isProjectDir(entry) {
+			continue
+		}
+
+		dir, _, err := s.resolveDir(entry.Name())
+		if err != nil {
+			fmt.Printf("Warning: failed to read project %s: %v\n", entry.Name(), err)
+			continue
+		}
+		_, result, err := s.readMetadata(entry.Name(), dir)
+		if err != nil {
+			// Log error but continue with other projects
+			fmt.Printf("Warning: failed to read project %s: %v\n", entry.Name(), err)
+			continue
+		}
+		if len(result.Applied) == 0 {
+			continue
+		}
+
+		if !dryRun {
+			if err := s.saveMigrated(entry.Name()); err != nil {
+				return results, fmt.Errorf("project %s: %w", entry.Name(), err)
+			}
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// Update updates a project's metadata. It fails with ErrConflict if the
+// metadata was saved by someone else since p was read.
+func (s *FSStore) Update(p *model.Project) error {
+	if p.Path == "" {
+		dir, _, err := s.resolveDir(p.Name)
+		if err != nil {
+			return err
+		}
+		p.Path = dir
+	}
+
+	unlock, err := s.lockProject(p.Name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if current, _, err := s.readMetadata(p.Name, p.Path); err == nil && current.Revision != p.Revision {
+		return fmt.Errorf("project %s: %w", p.Name, ErrConflict)
+	}
+	p.Revision++
+
+	if err := writeMetadata(p); err != nil {
+		p.Revision--
+		return err
+	}
+
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+	return nil
+}
+
+// writeMetadata atomically writes a project's .goshed.json at the current
+// schema version, with its tags normalized
+func writeMetadata(p *model.Project) error {
+	p.SchemaVersion = model.SchemaVersion
+	p.Tags = NormalizeTags(p.Tags)
+
+	metadataPath := filepath.Join(p.Path, ".goshed.json")
+	metadata, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal project metadata: %w", err)
+	}
+
+	if err := writeFileAtomic(metadataPath, metadata, 0644); err != nil {
+		return fmt.Errorf("failed to write project metadata: %w", err)
+	}
+
+	return nil
+}
+
+// CopyTo copies a project to a new location, leaving out goshed's own
+// files: the metadata, note bodies and snapshots
+func (s *FSStore) CopyTo(p *model.Project, dest string) error {
+	return filepath.Walk(p.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Get relative path
+		relPath, err := filepath.Rel(p.Path, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+
+		// Skip the same paths snapshots leave alone
+		if slices.Contains(snapshotExcluded, filepath.ToSlash(relPath)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Create destination path
+		destPath := filepath.Join(dest, relPath)
+
+		if info.IsDir() {
+			return os.MkdirAll(destPath, info.Mode())
+		}
+
+		// Copy file
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+
+		if err := os.WriteFile(destPath, data, info.Mode()); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", destPath, err)
+		}
+
+		return nil
+	})
+}
+
+// Rename renames a project's directory, its metadata and, when it was
+// generated from the project name, its module path along with every import
+// of it. A project registered by reference keeps its module. Forks of the
+// project are pointed at the new name. If any step fails, the changes made
+// so far are undone.
+func (s *FSStore) Rename(oldName, newName string) (*model.Project, error) {
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+	unlockOld, err := s.lockProject(oldName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockOld()
+	unlockNew, err := s.lockProject(newName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockNew()
+
+	p, err := s.Get(oldName)
+	if err != nil {
+		return nil, err
+	}
+	forks, unlockForks, err := s.lockForks(oldName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockForks()
+
+	oldDir := s.projectDir(oldName)
+	newDir := s.projectDir(newName)
+	if _, err := os.Stat(newDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s already exists", newName)
+	}
+
+	// The files of a project registered by reference stay where they are
+	// and belong to the user, so only its registration is renamed and its
+	// module is left alone
+	filesDir := newDir
+	var files, changed map[string][]byte
+	var newMod string
+	if p.External {
+		filesDir = p.Path
+	} else {
+		// Work out every file change before touching the disk
+		files, err = readModuleFiles(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module: %w", err)
+		}
+		newMod, changed, err = moduleRenameChanges(files, oldName, newName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.Rename(oldDir, newDir); err != nil {
+		return nil, fmt.Errorf("failed to rename project directory: %w", err)
+	}
+
+	// rollback restores the original files, directory name and forks
+	var written []string
+	var relinked []*model.Project
+	rollback := func() {
+		for _, name := range written {
+			writeModuleFile(filepath.Join(filesDir, name), files[name])
+		}
+		os.Rename(newDir, oldDir)
+		for _, fork := range relinked {
+			fork.ForkedFrom = oldName
+			writeMetadata(fork)
+		}
+	}
+
+	for name, data := range changed {
+		if err := writeModuleFile(filepath.Join(filesDir, name), data); err != nil {
+			rollback()
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		written = append(written, name)
+	}
+
+	for _, fork := range forks {
+		fork.ForkedFrom = newName
+		fork.Revision++
+		if err := writeMetadata(fork); err != nil {
+			rollback()
+			return nil, fmt.Errorf("failed to update fork %s: %w", fork.Name, err)
+		}
+		relinked = append(relinked, fork)
+	}
+
+	p.Name = newName
+	p.Path = filesDir
+	if newMod != "" {
+		p.Module = newMod
+	}
+	p.Revision++
+	if err := writeMetadata(p); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	s.updateIndex(func(idx *index) error {
+		delete(idx.Entries, oldName)
+		for _, fork := range forks {
+			if err := s.indexProject(idx, fork); err != nil {
+				return err
+			}
+		}
+		return s.indexProject(idx, p)
+	})
+
+	return p, nil
+}
+
+// lockForks locks and reads every project forked from name, including
+// scratch projects, so that their ForkedFrom can be rewritten
+func (s *FSStore) lockForks(name string) ([]*model.Project, func(), error) {
+	idx := s.loadIndex()
+	if _, err := s.refreshIndex(idx); err != nil {
+		return nil, nil, err
+	}
+
+	var forks []*model.Project
+	var unlocks []func()
+	unlockAll := func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}
+	for forkName, entry := range idx.Entries {
+		if entry.Project == nil || entry.Project.ForkedFrom != name || forkName == name {
+			continue
+		}
+		unlock, err := s.lockProject(forkName)
+		if err != nil {
+			unlockAll()
+			return nil, nil, err
+		}
+		unlocks = append(unlocks, unlock)
+
+		// Read again under the lock
+		fork, err := s.Get(forkName)
+		if err != nil {
+			unlockAll()
+			return nil, nil, err
+		}
+		if fork.ForkedFrom == name {
+			forks = append(forks, fork)
+		}
+	}
+	return forks, unlockAll, nil
+}
+
+// Fork creates a new project from a copy of an existing one. The copy's
+// module path and imports are rewritten for the new name, and its metadata
+// records the project it was forked from.
+func (s *FSStore) Fork(source, name string, keepNotes bool) (*model.Project, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	src, err := s.Get(source)
+	if err != nil {
+		return nil, err
+	}
+
+	forkDir := s.projectDir(name)
+	if _, err := os.Stat(forkDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+	if err := os.MkdirAll(forkDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create project directory: %w", err)
+	}
+
+	fork, err := s.populateFork(src, forkDir, name, keepNotes)
+	if err != nil {
+		os.RemoveAll(forkDir)
+		return nil, err
+	}
+
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, fork)
+	})
+
+	return fork, nil
+}
+
+// populateFork copies src into forkDir and writes the fork's module and
+// metadata
+func (s *FSStore) populateFork(src *model.Project, forkDir, name string, keepNotes bool) (*model.Project, error) {
+	if err := s.CopyTo(src, forkDir); err != nil {
+		return nil, fmt.Errorf("failed to copy project: %w", err)
+	}
+	if keepNotes {
+		// CopyTo leaves the note bodies out
+		data, err := os.ReadFile(filepath.Join(src.Path, notesFile))
+		if err == nil {
+			err = writeFileAtomic(filepath.Join(forkDir, notesFile), data, 0644)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to copy notes: %w", err)
+		}
+	}
+
+	files, err := readModuleFiles(forkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+	newMod, changed, err := moduleRenameChanges(files, src.Name, name)
+	if err != nil {
+		return nil, err
+	}
+	for filename, data := range changed {
+		if err := writeModuleFile(filepath.Join(forkDir, filename), data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+	}
+
+	fork := forkMetadata(src, name, keepNotes, newMod)
+	fork.Path = forkDir
+	if err := writeMetadata(fork); err != nil {
+		return nil, err
+	}
+
+	return fork, nil
+}
@@ -5,6 +5,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/crazywolf132/goshed/internal/model"
 )
@@ -28,30 +31,68 @@ func GetGitStatus(p *model.Project) (*GitStatus, error) {
 	}
 	status.Initialized = true
 
-	// Get current branch
-	cmd := exec.Command("git", "-C", p.Path, "branch", "--show-current")
+	// Get the current branch and working tree state in one call. The first
+	// line is the branch header, e.g. "## main...origin/main [ahead 1]".
+	cmd := exec.Command("git", "-C", p.Path, "status", "--porcelain", "--branch")
 	output, err := cmd.Output()
 	if err == nil {
-		status.Branch = string(output)
-	}
-
-	// Check if working directory is clean
-	cmd = exec.Command("git", "-C", p.Path, "status", "--porcelain")
-	output, err = cmd.Output()
-	if err == nil {
-		status.Clean = len(output) == 0
+		lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+		status.Branch = parseBranchHeader(lines[0])
+		status.Clean = len(lines) == 1
 	}
 
 	// Get remote URL
 	cmd = exec.Command("git", "-C", p.Path, "config", "--get", "remote.origin.url")
 	output, err = cmd.Output()
 	if err == nil {
-		status.Remote = string(output)
+		status.Remote = strings.TrimSpace(string(output))
 	}
 
 	return status, nil
 }
 
+// parseBranchHeader extracts the branch name from the header line of
+// "git status --porcelain --branch"
+func parseBranchHeader(header string) string {
+	header = strings.TrimPrefix(header, "## ")
+	header = strings.TrimPrefix(header, "No commits yet on ")
+	header = strings.TrimPrefix(header, "Initial commit on ")
+	if strings.HasPrefix(header, "HEAD (no branch)") {
+		return ""
+	}
+	if i := strings.Index(header, "..."); i >= 0 {
+		header = header[:i]
+	}
+	if i := strings.Index(header, " "); i >= 0 {
+		header = header[:i]
+	}
+	return header
+}
+
+// GetGitStatuses returns the Git status of each project, in order. Projects
+// are inspected concurrently; a nil entry means the status was unavailable.
+func GetGitStatuses(projects []*model.Project) []*GitStatus {
+	statuses := make([]*GitStatus, len(projects))
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+	for i, p := range projects {
+		wg.Add(1)
+		go func(i int, p *model.Project) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if status, err := GetGitStatus(p); err == nil {
+				statuses[i] = status
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
 // InitGit initializes a Git repository for the project
 func InitGit(p *model.Project) error {
 	cmd := exec.Command("git", "-C", p.Path, "init")
@@ -59,10 +100,15 @@ func InitGit(p *model.Project) error {
 		return fmt.Errorf("failed to initialize git repository: %w", err)
 	}
 
-	// Create .gitignore
+	// Create .gitignore, keeping one the project already has
 	gitignore := filepath.Join(p.Path, ".gitignore")
+	if _, err := os.Stat(gitignore); err == nil {
+		return nil
+	}
 	content := `# GoShed metadata
 .goshed.json
+.goshed.json.bak
+.goshed-snapshots/
 
 # Go build
 /bin/
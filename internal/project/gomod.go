@@ -0,0 +1,120 @@
+package project
+
+import (
+	"fmt"
+	"go/version"
+	"strings"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// DefaultGoVersion is the go directive written to a new project's go.mod
+// when none is given
+const DefaultGoVersion = "1.23"
+
+// DefaultModulePath returns the module path of a new project: its name,
+// under prefix if one is configured
+func DefaultModulePath(prefix, name string) string {
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// ValidateModulePath checks that path can be declared by a go.mod
+func ValidateModulePath(path string) error {
+	if err := module.CheckImportPath(path); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+	return nil
+}
+
+// ValidateGoVersion checks that version can be used in a go directive,
+// e.g. 1.23 or 1.23.4
+func ValidateGoVersion(v string) error {
+	if !modfile.GoVersionRE.MatchString(v) {
+		return fmt.Errorf("invalid go version %q: use a version such as 1.23 or 1.23.4", v)
+	}
+	return nil
+}
+
+// NormalizeToolchain returns the toolchain directive for name, accepting
+// both go1.23.4 and 1.23.4
+func NormalizeToolchain(name string) (string, error) {
+	if name == "default" {
+		return name, nil
+	}
+	v := strings.TrimPrefix(name, "go")
+	if !modfile.GoVersionRE.MatchString(v) {
+		return "", fmt.Errorf("invalid toolchain %q: use a toolchain such as go1.23.4", name)
+	}
+	return "go" + v, nil
+}
+
+// setModuleDefaults fills in the module path and go version of a new
+// project and normalizes its toolchain
+func setModuleDefaults(p *model.Project) error {
+	if p.Module == "" {
+		p.Module = p.Name
+	}
+	if p.GoVersion == "" {
+		p.GoVersion = DefaultGoVersion
+	}
+	if p.Toolchain != "" {
+		toolchain, err := NormalizeToolchain(p.Toolchain)
+		if err != nil {
+			return err
+		}
+		p.Toolchain = toolchain
+	}
+	return nil
+}
+
+// goModFile returns the go.mod of a new module. goVersion defaults to
+// DefaultGoVersion and the toolchain directive is left out when toolchain
+// is empty.
+func goModFile(modulePath, goVersion, toolchain string) ([]byte, error) {
+	if goVersion == "" {
+		goVersion = DefaultGoVersion
+	}
+	if err := ValidateModulePath(modulePath); err != nil {
+		return nil, err
+	}
+	if err := ValidateGoVersion(goVersion); err != nil {
+		return nil, err
+	}
+
+	f := new(modfile.File)
+	if err := f.AddModuleStmt(modulePath); err != nil {
+		return nil, fmt.Errorf("failed to write module directive: %w", err)
+	}
+	if err := f.AddGoStmt(goVersion); err != nil {
+		return nil, fmt.Errorf("failed to write go directive: %w", err)
+	}
+	if toolchain != "" {
+		name, err := NormalizeToolchain(toolchain)
+		if err != nil {
+			return nil, err
+		}
+		// The go command ignores a toolchain older than the go version
+		if name != "default" && version.Compare(name, "go"+goVersion) < 0 {
+			return nil, fmt.Errorf("toolchain %s is older than go %s", name, goVersion)
+		}
+		if err := f.AddToolchainStmt(name); err != nil {
+			return nil, fmt.Errorf("failed to write toolchain directive: %w", err)
+		}
+	}
+
+	data, err := f.Format()
+	if err != nil {
+		return nil, fmt.Errorf("failed to format go.mod: %w", err)
+	}
+	// Make sure what was generated parses back
+	if _, err := modfile.Parse("go.mod", data, nil); err != nil {
+		return nil, fmt.Errorf("generated an invalid go.mod: %w", err)
+	}
+	return data, nil
+}
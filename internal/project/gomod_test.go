@@ -0,0 +1,87 @@
+package project
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultModulePath(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", "api"},
+		{"  ", "api"},
+		{"github.com/me", "github.com/me/api"},
+		{"github.com/me/", "github.com/me/api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := DefaultModulePath(tt.prefix, "api"); got != tt.want {
+				t.Errorf("DefaultModulePath(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeToolchain(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"go1.23.4", "go1.23.4", false},
+		{"1.23.4", "go1.23.4", false},
+		{"go1.24rc1", "go1.24rc1", false},
+		{"default", "default", false},
+		{"latest", "", true},
+		{"go", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeToolchain(tt.in)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("NormalizeToolchain(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoModFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    string
+		goVersion string
+		toolchain string
+		want      string
+		wantErr   string
+	}{
+		{name: "defaults", module: "api", want: "module api\n\ngo " + DefaultGoVersion + "\n"},
+		{name: "go version", module: "example.com/api", goVersion: "1.22.3", want: "module example.com/api\n\ngo 1.22.3\n"},
+		{name: "toolchain", module: "api", goVersion: "1.23", toolchain: "1.23.4", want: "module api\n\ngo 1.23\n\ntoolchain go1.23.4\n"},
+		{name: "release candidate toolchain", module: "api", goVersion: "1.24rc1", toolchain: "go1.24rc2", want: "module api\n\ngo 1.24rc1\n\ntoolchain go1.24rc2\n"},
+		{name: "default toolchain", module: "api", toolchain: "default", want: "module api\n\ngo " + DefaultGoVersion + "\n\ntoolchain default\n"},
+		{name: "toolchain older than go", module: "api", goVersion: "1.23.4", toolchain: "go1.23.1", wantErr: "older than go 1.23.4"},
+		{name: "release candidate older than release", module: "api", goVersion: "1.24.0", toolchain: "go1.24rc1", wantErr: "older than go"},
+		{name: "invalid module", module: "not a module", wantErr: "invalid module path"},
+		{name: "invalid go version", module: "api", goVersion: "1.x", wantErr: "invalid go version"},
+		{name: "invalid toolchain", module: "api", toolchain: "latest", wantErr: "invalid toolchain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := goModFile(tt.module, tt.goVersion, tt.toolchain)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("goModFile error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("goModFile failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("goModFile =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+const (
+	// stateDir holds goshed's own bookkeeping inside a workspace root. It
+	// is never treated as a project.
+	stateDir = ".goshed"
+
+	indexFile    = "index.json"
+	indexVersion = 3
+)
+
+// Indexer is implemented by stores that keep an index of their projects
+type Indexer interface {
+	// Reindex rebuilds the index from the project metadata
+	Reindex() error
+}
+
+// index caches the metadata of every project in a workspace so that List
+// does not have to read each .goshed.json
+type index struct {
+	Version int `json:"version"`
+	// RootModTime is the workspace root's mtime when the entries were last
+	// reconciled with its directory listing
+	RootModTime time.Time              `json:"rootModTime"`
+	Entries     map[string]*indexEntry `json:"entries"`
+}
+
+// indexEntry is the cached metadata of one project, along with the state of
+// its .goshed.json when it was read. Projects whose metadata could not be
+// read have no Project, and Error says why.
+type indexEntry struct {
+	ModTime time.Time      `json:"modTime"`
+	Size    int64          `json:"size"`
+	Path    string         `json:"path"`
+	Project *model.Project `json:"project"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func newIndex() *index {
+	return &index{Version: indexVersion, Entries: make(map[string]*indexEntry)}
+}
+
+// isProjectDir reports whether a workspace root entry is a project directory
+func isProjectDir(entry os.DirEntry) bool {
+	return entry.IsDir() && !strings.HasPrefix(entry.Name(), ".")
+}
+
+func (s *FSStore) indexPath() string {
+	return filepath.Join(s.root, stateDir, indexFile)
+}
+
+// loadIndex reads the workspace index. A missing, unreadable or outdated
+// index is returned empty so that it gets rebuilt.
+func (s *FSStore) loadIndex() *index {
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return newIndex()
+	}
+
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion || idx.Entries == nil {
+		return newIndex()
+	}
+	return &idx
+}
+
+func (s *FSStore) saveIndex(idx *index) error {
+	if err := os.MkdirAll(filepath.Join(s.root, stateDir), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal project index: %w", err)
+	}
+	if err := writeFileAtomic(s.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write project index: %w", err)
+	}
+	return nil
+}
+
+// refreshIndex brings the index up to date with the workspace. The root
+// directory is only listed when its mtime shows projects were added or
+// removed, and a project's metadata is only re-read when its .goshed.json
+// has changed. It reports whether the index changed.
+func (s *FSStore) refreshIndex(idx *index) (bool, error) {
+	rootInfo, err := os.Stat(s.root)
+	if err != nil {
+		return false, fmt.Errorf("failed to read projects directory: %w", err)
+	}
+
+	changed := false
+	if !rootInfo.ModTime().Equal(idx.RootModTime) {
+		entries, err := os.ReadDir(s.root)
+		if err != nil {
+			return false, fmt.Errorf("failed to read projects directory: %w", err)
+		}
+
+		present := make(map[string]bool, len(entries))
+		for _, entry := range entries {
+			if !isProjectDir(entry) {
+				continue
+			}
+			present[entry.Name()] = true
+			if _, ok := idx.Entries[entry.Name()]; !ok {
+				// Force the entry to be read below
+				idx.Entries[entry.Name()] = &indexEntry{}
+			}
+		}
+		for name := range idx.Entries {
+			if !present[name] {
+				delete(idx.Entries, name)
+			}
+		}
+
+		idx.RootModTime = rootInfo.ModTime()
+		changed = true
+	}
+
+	for name, entry := range idx.Entries {
+		info, err := os.Stat(filepath.Join(entry.Path, ".goshed.json"))
+		unchanged := err == nil && info.ModTime().Equal(entry.ModTime) && info.Size() == entry.Size
+		if unchanged && entry.Project != nil {
+			continue
+		}
+		if unchanged && entry.Error != "" {
+			// Still broken; warn again without re-reading it
+			fmt.Fprintf(os.Stderr, "Warning: failed to read project %s: %s\n", name, entry.Error)
+			continue
+		}
+
+		p, err := s.Get(name)
+		if err != nil {
+			// Log error but continue with other projects. Warnings go to
+			// stderr so they cannot corrupt shell completion output.
+			fmt.Fprintf(os.Stderr, "Warning: failed to read project %s: %v\n", name, err)
+			idx.Entries[name] = s.unreadableEntry(name, err)
+			changed = true
+			continue
+		}
+		if err := s.indexProject(idx, p); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// unreadableEntry records a project whose metadata could not be read,
+// along with the state of its .goshed.json, so that it is read again once
+// the file changes
+func (s *FSStore) unreadableEntry(name string, readErr error) *indexEntry {
+	entry := &indexEntry{Path: s.projectDir(name), Error: readErr.Error()}
+	if dir, _, err := s.resolveDir(name); err == nil {
+		entry.Path = dir
+	}
+	if info, err := os.Stat(filepath.Join(entry.Path, ".goshed.json")); err == nil {
+		entry.ModTime = info.ModTime()
+		entry.Size = info.Size()
+	}
+	return entry
+}
+
+// indexProject records a project's current metadata in the index
+func (s *FSStore) indexProject(idx *index, p *model.Project) error {
+	info, err := os.Stat(filepath.Join(p.Path, ".goshed.json"))
+	if err != nil {
+		return fmt.Errorf("failed to stat project metadata: %w", err)
+	}
+
+	idx.Entries[p.Name] = &indexEntry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Path:    p.Path,
+		Project: p,
+	}
+	return nil
+}
+
+// updateIndex applies a change to the index and saves it. Index failures
+// are not fatal: the next List rebuilds whatever is stale.
+func (s *FSStore) updateIndex(apply func(idx *index) error) {
+	idx := s.loadIndex()
+	if err := apply(idx); err != nil {
+		return
+	}
+	s.saveIndex(idx)
+}
+
+// Reindex rebuilds the workspace index from scratch
+func (s *FSStore) Reindex() error {
+	idx := newIndex()
+	if _, err := s.refreshIndex(idx); err != nil {
+		return err
+	}
+	return s.saveIndex(idx)
+}
+
+// List returns all projects in the workspace, sorted by name. It reads from
+// the workspace index, refreshing any entries that are stale.
+func (s *FSStore) List() ([]*model.Project, error) {
+	idx := s.loadIndex()
+	changed, err := s.refreshIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := s.saveIndex(idx); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	projects := make([]*model.Project, 0, len(idx.Entries))
+	for name, entry := range idx.Entries {
+		if entry.Project == nil || entry.Project.Scratch {
+			continue
+		}
+		p := *entry.Project
+		p.Path = entry.Path
+		p.External = entry.Path != s.projectDir(name)
+		projects = append(projects, &p)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects, nil
+}
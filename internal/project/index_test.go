@@ -0,0 +1,53 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestListUnreadableProject(t *testing.T) {
+	s := NewFSStore(t.TempDir(), t.TempDir())
+	for _, name := range []string{"good", "broken"} {
+		p := &model.Project{Name: name, Template: "basic", Created: time.Now(), LastAccessed: time.Now()}
+		if err := s.Create(p); err != nil {
+			t.Fatalf("Create(%s) failed: %v", name, err)
+		}
+	}
+	metadata := filepath.Join(s.Root(), "broken", ".goshed.json")
+	original, err := os.ReadFile(metadata)
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{"corrupt", []byte("{broken"), []string{"good"}},
+		{"still corrupt", nil, []string{"good"}},
+		{"repaired", original, []string{"broken", "good"}},
+		{"negative schema version", []byte(`{"schemaVersion":-1,"name":"broken"}`), []string{"good"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.data != nil {
+				if err := os.WriteFile(metadata, tt.data, 0644); err != nil {
+					t.Fatalf("failed to write metadata: %v", err)
+				}
+			}
+			projects, err := s.List()
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if got := names(projects); !slices.Equal(got, tt.want) {
+				t.Errorf("List = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
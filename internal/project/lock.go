@@ -0,0 +1,313 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	lockDir           = "locks"
+	workspaceLockFile = "workspace.lock"
+
+	// lockTimeout is how long to wait for a lock held by another process
+	// before giving up. Locks are only held for the duration of a single
+	// store operation, so contention is normally brief.
+	lockTimeout      = 2 * time.Second
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// WorkspaceLocker is implemented by stores that can lock a whole workspace
+// for bulk operations
+type WorkspaceLocker interface {
+	// LockWorkspace takes the workspace lock. Until the returned function
+	// is called, other processes cannot modify any project.
+	LockWorkspace() (unlock func(), err error)
+}
+
+// LockInfo describes the process holding a lock
+type LockInfo struct {
+	PID      int       `json:"pid"`
+	Command  string    `json:"command"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// LockError is returned when a lock is held by another process
+type LockError struct {
+	Resource string
+	Holder   LockInfo
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("%s is locked by process %d (%s) since %s",
+		e.Resource, e.Holder.PID, e.Holder.Command, e.Holder.Acquired.Format(time.RFC3339))
+}
+
+func (s *FSStore) projectLockPath(name string) string {
+	return filepath.Join(s.root, stateDir, lockDir, name+".lock")
+}
+
+func (s *FSStore) workspaceLockPath() string {
+	return filepath.Join(s.root, stateDir, workspaceLockFile)
+}
+
+// LockWorkspace takes the workspace lock for a bulk operation. It waits
+// for operations on single projects to finish.
+func (s *FSStore) LockWorkspace() (func(), error) {
+	return acquireLock(s.workspaceLockPath(), "workspace "+s.root, true)
+}
+
+// lockProject takes the lock for a single project. It also takes a shared
+// hold on the workspace lock, so that it cannot run while another process
+// holds the workspace lock, nor the other way round. Every change to a
+// project takes this lock first, so it also rejects names that are not a
+// single directory in the workspace.
+func (s *FSStore) lockProject(name string) (func(), error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	unlockWorkspace, err := acquireLock(s.workspaceLockPath(), "workspace "+filepath.Base(s.root), false)
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := acquireLock(s.projectLockPath(name), "project "+name, true)
+	if err != nil {
+		unlockWorkspace()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		unlockWorkspace()
+	}, nil
+}
+
+// errLocked is returned by lockFile when another process holds a
+// conflicting lock
+var errLocked = errors.New("locked by another process")
+
+// heldLock is a lock file this process has locked, with the number of
+// shared and exclusive holds on it
+type heldLock struct {
+	f         *os.File
+	shared    int
+	exclusive int
+}
+
+var (
+	// held tracks the locks this process holds, so that a store operation
+	// can call another one that takes the same lock
+	held   = make(map[string]*heldLock)
+	heldMu sync.Mutex
+)
+
+// acquireLock takes an OS file lock on path, shared or exclusive, waiting
+// up to lockTimeout for other processes. The lock is released by the OS
+// when the process exits, so a crash never leaves a lock behind. Exclusive
+// holders record themselves in the file for error messages and doctor.
+// Locks are reentrant within a process, and an exclusive request upgrades
+// a shared hold.
+func acquireLock(path, resource string, exclusive bool) (func(), error) {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+
+	h := held[path]
+	if h == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create lock directory: %w", err)
+		}
+		// Lock files are never removed: a process waiting on a removed
+		// file would lock a file nobody else can see
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock %s: %w", path, err)
+		}
+		if err := waitLock(f, resource, exclusive); err != nil {
+			f.Close()
+			return nil, err
+		}
+		h = &heldLock{f: f}
+		held[path] = h
+	} else if exclusive && h.exclusive == 0 {
+		if err := waitLock(h.f, resource, true); err != nil {
+			// A failed upgrade may have dropped the shared lock
+			shareLock(h.f)
+			return nil, err
+		}
+	}
+
+	if exclusive {
+		if h.exclusive == 0 {
+			writeLockHolder(h.f)
+		}
+		h.exclusive++
+	} else {
+		h.shared++
+	}
+	return func() { releaseLock(path, exclusive) }, nil
+}
+
+// waitLock retries lockFile until it succeeds or lockTimeout passes
+func waitLock(f *os.File, resource string, exclusive bool) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := lockFile(f, exclusive)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, errLocked) {
+			return fmt.Errorf("failed to lock %s: %w", resource, err)
+		}
+		if time.Now().After(deadline) {
+			if holder, err := readLock(f.Name()); err == nil && holder.PID != 0 {
+				return &LockError{Resource: resource, Holder: *holder}
+			}
+			return fmt.Errorf("%s is %w", resource, errLocked)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// writeLockHolder records the current process in a lock file it holds
+// exclusively. The record is informational, so failures are ignored.
+func writeLockHolder(f *os.File) {
+	data, err := json.Marshal(LockInfo{
+		PID:      os.Getpid(),
+		Command:  strings.Join(os.Args, " "),
+		Acquired: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt(data, 0)
+	}
+}
+
+// releaseLock drops one hold on a lock. Once the last exclusive hold is
+// gone the lock is downgraded to shared or unlocked, and the holder record
+// is cleared.
+func releaseLock(path string, exclusive bool) {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+
+	h := held[path]
+	if h == nil {
+		return
+	}
+	if exclusive {
+		h.exclusive--
+		if h.exclusive == 0 {
+			h.f.Truncate(0)
+		}
+	} else {
+		h.shared--
+	}
+
+	switch {
+	case h.exclusive == 0 && h.shared == 0:
+		unlockFile(h.f)
+		h.f.Close()
+		delete(held, path)
+	case exclusive && h.exclusive == 0:
+		shareLock(h.f)
+	}
+}
+
+// readLock returns the process recorded in a lock file. The PID is zero
+// when no process holds the lock exclusively.
+func readLock(path string) (*LockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var info LockInfo
+	if len(data) == 0 {
+		return &info, nil
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse lock: %w", err)
+	}
+	return &info, nil
+}
+
+// staleLock reports whether path records a holder although no process
+// holds the lock, which happens when a process exits without releasing it.
+// Locks this process holds are never stale.
+func staleLock(path string) (*LockInfo, bool, error) {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+	if held[path] != nil {
+		return nil, false, nil
+	}
+
+	holder, err := readLock(path)
+	if err != nil || holder.PID == 0 {
+		return holder, false, err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	if err := lockFile(f, true); err != nil {
+		if errors.Is(err, errLocked) {
+			return holder, false, nil
+		}
+		return nil, false, err
+	}
+	defer unlockFile(f)
+	return holder, true, nil
+}
+
+// clearLock removes the holder record from a lock file nobody holds
+func clearLock(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := lockFile(f, true); err != nil {
+		return err
+	}
+	defer unlockFile(f)
+	return f.Truncate(0)
+}
+
+// writeFileAtomic replaces a file by writing a temporary file in the same
+// directory and renaming it into place, so readers never observe a partial
+// write and a crash leaves the old contents intact
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
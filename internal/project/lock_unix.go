@@ -0,0 +1,48 @@
+//go:build !windows
+
+package project
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes a shared or exclusive flock on f without blocking. It
+// converts a lock f already holds, but flock may drop the old lock when
+// the conversion fails.
+func lockFile(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, syscall.EINTR):
+			continue
+		case errors.Is(err, syscall.EWOULDBLOCK):
+			return errLocked
+		default:
+			return err
+		}
+	}
+}
+
+// shareLock takes a shared flock on f, waiting for exclusive holders. It
+// converts an exclusive lock f already holds.
+func shareLock(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_SH)
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+// unlockFile releases the lock on f
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
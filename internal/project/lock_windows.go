@@ -0,0 +1,42 @@
+//go:build windows
+
+package project
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes a shared or exclusive LockFileEx lock on f without
+// blocking. Windows cannot convert a lock in place, so a lock f already
+// holds is released first.
+func lockFile(f *os.File, exclusive bool) error {
+	var flags uint32 = windows.LOCKFILE_FAIL_IMMEDIATELY
+	if exclusive {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+
+	h := windows.Handle(f.Fd())
+	unlockFile(f)
+	err := windows.LockFileEx(h, flags, 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// shareLock takes a shared lock on f, waiting for exclusive holders. It
+// replaces a lock f already holds.
+func shareLock(f *os.File) error {
+	h := windows.Handle(f.Fd())
+	unlockFile(f)
+	return windows.LockFileEx(h, 0, 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
+
+// unlockFile releases the lock on f
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
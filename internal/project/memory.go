@@ -0,0 +1,544 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// MemoryStore is a Store that keeps projects and their files in memory.
+// Projects have no Path; CopyTo writes their files to disk.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	projects map[string]*model.Project
+	files    map[string]map[string][]byte
+	archived map[string]*memoryArchive
+	trash    []*memoryTrashEntry
+}
+
+// memoryTrashEntry is a removed project and its files
+type memoryTrashEntry struct {
+	TrashEntry
+	files map[string][]byte
+}
+
+// memoryArchive is an archived project and its files
+type memoryArchive struct {
+	ArchivedProject
+	files map[string][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		projects: make(map[string]*model.Project),
+		files:    make(map[string]map[string][]byte),
+		archived: make(map[string]*memoryArchive),
+	}
+}
+
+// Create creates a new project with the given configuration
+func (s *MemoryStore) Create(p *model.Project) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[p.Name]; ok {
+		return fmt.Errorf("project %s already exists", p.Name)
+	}
+
+	if err := setModuleDefaults(p); err != nil {
+		return err
+	}
+	files, _, err := projectFiles(p)
+	if err != nil {
+		return err
+	}
+
+	p.SchemaVersion = model.SchemaVersion
+	p.Tags = NormalizeTags(p.Tags)
+	s.projects[p.Name] = cloneProject(p)
+	s.files[p.Name] = files
+	return nil
+}
+
+// Get retrieves a project by name
+func (s *MemoryStore) Get(name string) (*model.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[name]
+	if !ok {
+		return nil, fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+	return cloneProject(p), nil
+}
+
+// Update updates a project's metadata. It fails with ErrConflict if the
+// project was updated since p was read.
+func (s *MemoryStore) Update(p *model.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.projects[p.Name]
+	if !ok {
+		return fmt.Errorf("project %s %w", p.Name, ErrNotFound)
+	}
+	if current.Revision != p.Revision {
+		return fmt.Errorf("project %s: %w", p.Name, ErrConflict)
+	}
+	p.Revision++
+	p.SchemaVersion = model.SchemaVersion
+	p.Tags = NormalizeTags(p.Tags)
+	s.projects[p.Name] = cloneProject(p)
+	return nil
+}
+
+// CopyTo writes a project's files to a directory on disk, without its note
+// bodies
+func (s *MemoryStore) CopyTo(p *model.Project, dest string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, ok := s.files[p.Name]
+	if !ok {
+		return fmt.Errorf("project %s %w", p.Name, ErrNotFound)
+	}
+
+	for filename, content := range files {
+		// Leave out goshed's own files, as FSStore does
+		if slices.Contains(snapshotExcluded, strings.SplitN(filename, "/", 2)[0]) {
+			continue
+		}
+		destPath := filepath.Join(dest, filename)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+		}
+		if err := os.WriteFile(destPath, content, 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", destPath, err)
+		}
+	}
+
+	return nil
+}
+
+// Remove moves a project into the trash
+func (s *MemoryStore) Remove(name, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[name]
+	if !ok {
+		return fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+
+	now := time.Now()
+	s.trash = append(s.trash, &memoryTrashEntry{
+		TrashEntry: TrashEntry{
+			ID:        fmt.Sprintf("%s-%d", name, now.UnixNano()),
+			Project:   p,
+			RemovedAt: now,
+			Reason:    reason,
+		},
+		files: s.files[name],
+	})
+	delete(s.projects, name)
+	delete(s.files, name)
+	return nil
+}
+
+// ListTrash returns the projects in the trash, most recently removed first
+func (s *MemoryStore) ListTrash() ([]*TrashEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trash := make([]*TrashEntry, 0, len(s.trash))
+	for i := len(s.trash) - 1; i >= 0; i-- {
+		entry := s.trash[i].TrashEntry
+		entry.Project = cloneProject(entry.Project)
+		trash = append(trash, &entry)
+	}
+	return trash, nil
+}
+
+// RestoreTrash brings the most recently removed project with the given name
+// back from the trash
+func (s *MemoryStore) RestoreTrash(name string) (*model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[name]; ok {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+	for i := len(s.trash) - 1; i >= 0; i-- {
+		entry := s.trash[i]
+		if entry.Project.Name != name {
+			continue
+		}
+		// A discarded scratch project that is restored was wanted after all
+		entry.Project.Scratch = false
+		s.projects[name] = entry.Project
+		s.files[name] = entry.files
+		s.trash = append(s.trash[:i], s.trash[i+1:]...)
+		return cloneProject(entry.Project), nil
+	}
+	return nil, fmt.Errorf("project %s is not in the trash", name)
+}
+
+// EmptyTrash permanently deletes the projects that have been in the trash
+// for longer than olderThan
+func (s *MemoryStore) EmptyTrash(olderThan time.Duration) ([]*TrashEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	var kept []*memoryTrashEntry
+	var emptied []*TrashEntry
+	for _, entry := range s.trash {
+		if entry.RemovedAt.After(cutoff) {
+			kept = append(kept, entry)
+			continue
+		}
+		e := entry.TrashEntry
+		emptied = append(emptied, &e)
+	}
+	s.trash = kept
+	return emptied, nil
+}
+
+// Rename renames a project, rewriting its module path and imports when the
+// module path was generated from the project name and the project was not
+// registered by reference
+func (s *MemoryStore) Rename(oldName, newName string) (*model.Project, error) {
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[oldName]
+	if !ok {
+		return nil, fmt.Errorf("project %s %w", oldName, ErrNotFound)
+	}
+	if _, ok := s.projects[newName]; ok {
+		return nil, fmt.Errorf("project %s already exists", newName)
+	}
+
+	files := s.files[oldName]
+	var newMod string
+	if !p.External {
+		var changed map[string][]byte
+		var err error
+		newMod, changed, err = moduleRenameChanges(files, oldName, newName)
+		if err != nil {
+			return nil, err
+		}
+		for name, data := range changed {
+			files[name] = data
+		}
+	}
+
+	p.Name = newName
+	if newMod != "" {
+		p.Module = newMod
+	}
+	p.Revision++
+	for _, fork := range s.projects {
+		if fork.ForkedFrom == oldName {
+			fork.ForkedFrom = newName
+			fork.Revision++
+		}
+	}
+	delete(s.projects, oldName)
+	delete(s.files, oldName)
+	s.projects[newName] = p
+	s.files[newName] = files
+
+	return cloneProject(p), nil
+}
+
+// Fork creates a new project from a copy of an existing one
+func (s *MemoryStore) Fork(source, name string, keepNotes bool) (*model.Project, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, ok := s.projects[source]
+	if !ok {
+		return nil, fmt.Errorf("project %s %w", source, ErrNotFound)
+	}
+	if _, ok := s.projects[name]; ok {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+
+	files := make(map[string][]byte, len(s.files[source]))
+	for filename, data := range s.files[source] {
+		files[filename] = data
+	}
+	newMod, changed, err := moduleRenameChanges(files, source, name)
+	if err != nil {
+		return nil, err
+	}
+	for filename, data := range changed {
+		files[filename] = data
+	}
+	if !keepNotes {
+		delete(files, notesFile)
+	}
+
+	fork := forkMetadata(src, name, keepNotes, newMod)
+	fork.SchemaVersion = model.SchemaVersion
+	fork.Tags = NormalizeTags(fork.Tags)
+	s.projects[name] = fork
+	s.files[name] = files
+
+	return cloneProject(fork), nil
+}
+
+// Archive moves a project out of the active projects
+func (s *MemoryStore) Archive(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[name]
+	if !ok {
+		return fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+	if _, ok := s.archived[name]; ok {
+		return fmt.Errorf("an archive of %s already exists", name)
+	}
+
+	s.archived[name] = &memoryArchive{
+		ArchivedProject: ArchivedProject{Project: p, ArchivedAt: time.Now()},
+		files:           s.files[name],
+	}
+	delete(s.projects, name)
+	delete(s.files, name)
+	return nil
+}
+
+// Unarchive moves an archived project back into the active projects
+func (s *MemoryStore) Unarchive(name string) (*model.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.archived[name]
+	if !ok {
+		return nil, fmt.Errorf("no archive of %s found", name)
+	}
+	if _, ok := s.projects[name]; ok {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+
+	s.projects[name] = a.Project
+	s.files[name] = a.files
+	delete(s.archived, name)
+	return cloneProject(a.Project), nil
+}
+
+// Adopt reads the files of an existing directory into a new project. The
+// directory itself is never changed, whether or not move is set.
+func (s *MemoryStore) Adopt(dir string, p *model.Project, move bool) (*model.Project, error) {
+	if err := ValidateName(p.Name); err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == ".goshed.json" || d.Name() == ".goshed.json.bak" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.projects[p.Name]; ok {
+		return nil, fmt.Errorf("project %s already exists", p.Name)
+	}
+
+	p.SchemaVersion = model.SchemaVersion
+	p.Tags = NormalizeTags(p.Tags)
+	s.projects[p.Name] = cloneProject(p)
+	s.files[p.Name] = files
+	return cloneProject(p), nil
+}
+
+// NoteBodies returns the markdown bodies of a project's notes, keyed by
+// note ID
+func (s *MemoryStore) NoteBodies(name string) (map[int]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.projects[name]; !ok {
+		return nil, fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+	return parseNotes(s.files[name][notesFile])
+}
+
+// AddNote appends a note to a project's journal
+func (s *MemoryStore) AddNote(name, text, body string) (*model.Note, error) {
+	var note *model.Note
+	err := s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		var err error
+		note, err = addNote(p, bodies, text, body)
+		return err
+	})
+	return note, err
+}
+
+// EditNote replaces the text and body of a note
+func (s *MemoryStore) EditNote(name string, id int, text, body string) (*model.Note, error) {
+	var note *model.Note
+	err := s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		var err error
+		note, err = editNote(p, bodies, id, text, body)
+		return err
+	})
+	return note, err
+}
+
+// DeleteNote removes a note from a project's journal
+func (s *MemoryStore) DeleteNote(name string, id int) error {
+	return s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		return deleteNote(p, bodies, id)
+	})
+}
+
+func (s *MemoryStore) updateNotes(name string, apply func(p *model.Project, bodies map[int]string) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.projects[name]
+	if !ok {
+		return fmt.Errorf("project %s %w", name, ErrNotFound)
+	}
+	p := cloneProject(stored)
+	files := s.files[name]
+	bodies, err := parseNotes(files[notesFile])
+	if err != nil {
+		return err
+	}
+
+	if err := apply(p, bodies); err != nil {
+		return err
+	}
+
+	if len(bodies) == 0 {
+		delete(files, notesFile)
+	} else {
+		files[notesFile] = formatNotes(p.Notes, bodies)
+	}
+	p.LastAccessed = time.Now()
+	p.Revision++
+	s.projects[name] = p
+	return nil
+}
+
+// ListArchived returns the archived projects sorted by name
+func (s *MemoryStore) ListArchived() ([]*ArchivedProject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	archived := make([]*ArchivedProject, 0, len(s.archived))
+	for _, a := range s.archived {
+		archived = append(archived, &ArchivedProject{
+			Project:    cloneProject(a.Project),
+			ArchivedAt: a.ArchivedAt,
+		})
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].Project.Name < archived[j].Project.Name
+	})
+	return archived, nil
+}
+
+// List returns all projects sorted by name
+func (s *MemoryStore) List() ([]*model.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]*model.Project, 0, len(s.projects))
+	for _, p := range s.projects {
+		if p.Scratch {
+			continue
+		}
+		projects = append(projects, cloneProject(p))
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects, nil
+}
+
+// cloneProject returns a copy of p that shares no slices, maps or pointers
+// with it, so callers cannot modify stored projects without calling Update
+func cloneProject(p *model.Project) *model.Project {
+	c := *p
+	c.Path = ""
+	if p.Tags != nil {
+		c.Tags = append([]string(nil), p.Tags...)
+	}
+	if p.Properties != nil {
+		c.Properties = maps.Clone(p.Properties)
+	}
+	if p.Notes != nil {
+		c.Notes = append([]model.Note(nil), p.Notes...)
+		for i := range c.Notes {
+			c.Notes[i].Edited = cloneTime(c.Notes[i].Edited)
+		}
+	}
+	c.LastModified = cloneTime(p.LastModified)
+	c.LastCommit = cloneTime(p.LastCommit)
+	c.ExpiresAt = cloneTime(p.ExpiresAt)
+	if p.Size != nil {
+		size := *p.Size
+		c.Size = &size
+	}
+	return &c
+}
+
+// cloneTime returns a copy of t, or nil if t is nil
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,176 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// migration upgrades raw .goshed.json metadata by one schema version
+type migration struct {
+	description string
+	apply       func(raw map[string]any) error
+}
+
+// migrations[i] upgrades metadata from schema version i to i+1. Append new
+// migrations here whenever model.SchemaVersion is bumped.
+var migrations = []migration{
+	{
+		description: "add schemaVersion and replace missing tags with an empty list",
+		apply: func(raw map[string]any) error {
+			if raw["tags"] == nil {
+				raw["tags"] = []any{}
+			}
+			return nil
+		},
+	},
+	{
+		description: "turn the notes string into a list of timestamped entries",
+		apply: func(raw map[string]any) error {
+			var text string
+			switch notes := raw["notes"].(type) {
+			case nil:
+			case string:
+				text = notes
+			case []any:
+				// Already a list, e.g. edited by hand; keep it as it is
+				return nil
+			default:
+				// Refuse rather than drop notes we cannot read
+				return fmt.Errorf("notes is neither a string nor a list: %s", compactJSON(notes))
+			}
+			if text == "" {
+				raw["notes"] = []any{}
+				return nil
+			}
+
+			// The old notes were last written no later than the last access
+			created := raw["lastAccessed"]
+			if created == nil {
+				created = raw["created"]
+			}
+			raw["notes"] = []any{
+				map[string]any{"id": 1, "created": created, "text": text},
+			}
+			return nil
+		},
+	},
+	{
+		description: "lowercase and trim tags, dropping empty and duplicate ones",
+		apply: func(raw map[string]any) error {
+			list, ok := raw["tags"].([]any)
+			if !ok {
+				if raw["tags"] == nil {
+					raw["tags"] = []any{}
+					return nil
+				}
+				return fmt.Errorf("tags is not a list: %s", compactJSON(raw["tags"]))
+			}
+
+			tags := make([]string, 0, len(list))
+			for _, tag := range list {
+				text, ok := tag.(string)
+				if !ok {
+					return fmt.Errorf("tag is not a string: %s", compactJSON(tag))
+				}
+				tags = append(tags, text)
+			}
+
+			normalized := make([]any, 0, len(tags))
+			for _, tag := range NormalizeTags(tags) {
+				normalized = append(normalized, tag)
+			}
+			raw["tags"] = normalized
+			return nil
+		},
+	},
+}
+
+// ErrSchemaTooNew is returned for metadata written by a newer goshed
+var ErrSchemaTooNew = errors.New("unsupported schema version")
+
+// MigrationResult describes the migrations applied, or due, for a project
+type MigrationResult struct {
+	Name    string
+	From    int
+	To      int
+	Applied []string
+}
+
+// Migrator is implemented by stores whose metadata can predate the current
+// schema
+type Migrator interface {
+	// Migrate upgrades the metadata of every project to the current schema.
+	// With dryRun set it only reports what would change.
+	Migrate(dryRun bool) ([]MigrationResult, error)
+}
+
+// decodeMetadata parses .goshed.json data, upgrading it to the current
+// schema version. It returns the upgraded project and the description of
+// each migration that was applied.
+func decodeMetadata(data []byte) (*model.Project, *MigrationResult, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse project metadata: %w", err)
+	}
+
+	version := 0
+	switch v := raw["schemaVersion"].(type) {
+	case nil:
+		// Written before the schema was versioned
+	case float64:
+		if v < 0 || v != math.Trunc(v) {
+			return nil, nil, fmt.Errorf("failed to parse project metadata: invalid schema version %s", compactJSON(v))
+		}
+		if v > model.SchemaVersion {
+			return nil, nil, fmt.Errorf("%w: project metadata has schema version %s, but this goshed only supports up to %d; please upgrade goshed", ErrSchemaTooNew, compactJSON(v), model.SchemaVersion)
+		}
+		version = int(v)
+	default:
+		return nil, nil, fmt.Errorf("failed to parse project metadata: invalid schema version %s", compactJSON(v))
+	}
+
+	result := &MigrationResult{From: version, To: model.SchemaVersion}
+	for v := version; v < model.SchemaVersion; v++ {
+		m := migrations[v]
+		if err := m.apply(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to migrate project metadata from version %d: %w", v, err)
+		}
+		result.Applied = append(result.Applied, m.description)
+	}
+	raw["schemaVersion"] = model.SchemaVersion
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal project metadata: %w", err)
+	}
+
+	var p model.Project
+	if err := json.Unmarshal(upgraded, &p); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse project metadata: %w", err)
+	}
+	return &p, result, nil
+}
+
+// decodeEmbedded decodes project metadata embedded in another document,
+// such as a trash entry or an archive sidecar, upgrading it to the current
+// schema version
+func decodeEmbedded(data json.RawMessage) (*model.Project, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	p, _, err := decodeMetadata(data)
+	return p, err
+}
+
+// compactJSON renders a decoded JSON value for an error message
+func compactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,125 @@
+package project
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestDecodeMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		from      int
+		applied   int
+		wantTags  []string
+		wantNotes []string
+	}{
+		{
+			name:      "version 0 without tags or notes",
+			data:      `{"name":"api","created":"2024-01-01T00:00:00Z"}`,
+			from:      0,
+			applied:   3,
+			wantTags:  []string{},
+			wantNotes: nil,
+		},
+		{
+			name:      "version 0 notes string",
+			data:      `{"name":"api","created":"2024-01-01T00:00:00Z","lastAccessed":"2024-02-01T00:00:00Z","notes":"try grpc"}`,
+			from:      0,
+			applied:   3,
+			wantTags:  []string{},
+			wantNotes: []string{"try grpc"},
+		},
+		{
+			name:      "version 1 notes already a list",
+			data:      `{"schemaVersion":1,"name":"api","tags":["web"],"notes":[{"id":1,"text":"kept"}]}`,
+			from:      1,
+			applied:   2,
+			wantTags:  []string{"web"},
+			wantNotes: []string{"kept"},
+		},
+		{
+			name:     "version 2 tags are normalized",
+			data:     `{"schemaVersion":2,"name":"api","tags":[" Web ","web","","CLI"],"notes":[]}`,
+			from:     2,
+			applied:  1,
+			wantTags: []string{"web", "cli"},
+		},
+		{
+			name:     "current version is left alone",
+			data:     `{"schemaVersion":3,"name":"api","tags":["web"],"notes":[]}`,
+			from:     3,
+			applied:  0,
+			wantTags: []string{"web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, result, err := decodeMetadata([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("decodeMetadata failed: %v", err)
+			}
+			if p.SchemaVersion != model.SchemaVersion {
+				t.Errorf("SchemaVersion = %d, want %d", p.SchemaVersion, model.SchemaVersion)
+			}
+			if result.From != tt.from || result.To != model.SchemaVersion || len(result.Applied) != tt.applied {
+				t.Errorf("result = %+v, want %d migrations from %d", result, tt.applied, tt.from)
+			}
+			if !reflect.DeepEqual(p.Tags, tt.wantTags) {
+				t.Errorf("Tags = %q, want %q", p.Tags, tt.wantTags)
+			}
+			var notes []string
+			for _, n := range p.Notes {
+				notes = append(notes, n.Text)
+			}
+			if !reflect.DeepEqual(notes, tt.wantNotes) {
+				t.Errorf("Notes = %q, want %q", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestDecodeMetadataNoteDate(t *testing.T) {
+	p, _, err := decodeMetadata([]byte(`{"name":"api","created":"2024-01-01T00:00:00Z","notes":"old"}`))
+	if err != nil {
+		t.Fatalf("decodeMetadata failed: %v", err)
+	}
+	if len(p.Notes) != 1 || p.Notes[0].ID != 1 || !p.Notes[0].Created.Equal(p.Created) {
+		t.Errorf("Notes = %+v, want one note dated when the project was created", p.Notes)
+	}
+}
+
+func TestDecodeMetadataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"invalid JSON", `{"name":`, "failed to parse project metadata"},
+		{"notes neither string nor list", `{"name":"api","notes":{"text":"x"}}`, "notes is neither a string nor a list"},
+		{"tags not a list", `{"schemaVersion":2,"name":"api","tags":"web"}`, "tags is not a list"},
+		{"tag not a string", `{"schemaVersion":2,"name":"api","tags":["web",7]}`, "tag is not a string: 7"},
+		{"negative schema version", `{"schemaVersion":-1,"name":"api"}`, "invalid schema version -1"},
+		{"fractional schema version", `{"schemaVersion":1.5,"name":"api"}`, "invalid schema version 1.5"},
+		{"schema version string", `{"schemaVersion":"2","name":"api"}`, `invalid schema version "2"`},
+		{"schema version bool", `{"schemaVersion":true,"name":"api"}`, "invalid schema version true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeMetadata([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("decodeMetadata error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, data := range []string{`{"schemaVersion":99,"name":"api"}`, `{"schemaVersion":1e300,"name":"api"}`} {
+		if _, _, err := decodeMetadata([]byte(data)); !errors.Is(err, ErrSchemaTooNew) {
+			t.Errorf("decodeMetadata(%s) error = %v, want ErrSchemaTooNew", data, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package project
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// renamedModulePath returns the module path a project should have once it
+// is renamed from oldName to newName. Only module paths generated from the
+// project name are changed; a custom module path is left alone.
+func renamedModulePath(modPath, oldName, newName string) (string, bool) {
+	if modPath == oldName {
+		return newName, true
+	}
+	if strings.HasSuffix(modPath, "/"+oldName) {
+		return strings.TrimSuffix(modPath, oldName) + newName, true
+	}
+	return modPath, false
+}
+
+// modulePath returns the module path declared by the go.mod in files, or
+// an empty string if there is none
+func modulePath(files map[string][]byte) string {
+	data, ok := files["go.mod"]
+	if !ok {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
+
+// readModuleFiles reads go.mod and every Go source file of the module in
+// dir, keyed by their path relative to dir. Hidden directories and vendor
+// are skipped.
+func readModuleFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		if rel != "go.mod" && !strings.HasSuffix(d.Name(), ".go") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		files[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// writeModuleFile replaces a file of a module, keeping its permissions
+func writeModuleFile(path string, data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return writeFileAtomic(path, data, perm)
+}
+
+// rewriteModule changes the module path declared in go.mod to newPath and
+// rewrites every import of the module's packages in the Go files. Other
+// files are left alone. Only the files that changed are returned.
+func rewriteModule(files map[string][]byte, oldPath, newPath string) (map[string][]byte, error) {
+	changed := make(map[string][]byte)
+
+	for name, data := range files {
+		if name == "go.mod" {
+			f, err := modfile.Parse(name, data, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+			}
+			if err := f.AddModuleStmt(newPath); err != nil {
+				return nil, fmt.Errorf("failed to set module path: %w", err)
+			}
+			out, err := f.Format()
+			if err != nil {
+				return nil, fmt.Errorf("failed to format go.mod: %w", err)
+			}
+			changed[name] = out
+			continue
+		}
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+
+		out, ok, err := rewriteImports(name, data, oldPath, newPath)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed[name] = out
+		}
+	}
+
+	return changed, nil
+}
+
+// rewriteImports replaces imports of oldPath and its packages with the same
+// packages under newPath. Only the import path literals are touched, so the
+// rest of the file keeps its exact formatting.
+func rewriteImports(name string, src []byte, oldPath, newPath string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if path != oldPath && !strings.HasPrefix(path, oldPath+"/") {
+			continue
+		}
+		edits = append(edits, edit{
+			start: fset.Position(imp.Path.Pos()).Offset,
+			end:   fset.Position(imp.Path.End()).Offset,
+			text:  strconv.Quote(newPath + strings.TrimPrefix(path, oldPath)),
+		})
+	}
+	if len(edits) == 0 {
+		return nil, false, nil
+	}
+
+	// Apply from the end of the file so earlier offsets stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+
+	return out, true, nil
+}
@@ -0,0 +1,137 @@
+package project
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenamedModulePath(t *testing.T) {
+	tests := []struct {
+		modPath string
+		want    string
+		ok      bool
+	}{
+		{"old", "new", true},
+		{"github.com/me/old", "github.com/me/new", true},
+		{"github.com/me/bold", "github.com/me/bold", false},
+		{"github.com/me/custom", "github.com/me/custom", false},
+		{"old/v2", "old/v2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.modPath, func(t *testing.T) {
+			got, ok := renamedModulePath(tt.modPath, "old", "new")
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("renamedModulePath(%q) = %q, %v, want %q, %v", tt.modPath, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestModuleRenameChanges(t *testing.T) {
+	main := func(imp string) []byte {
+		return []byte("package main\n\nimport (\n\t\"fmt\"\n\t\"" + imp + "\" // keep\n)\n\nfunc main() { fmt.Println(x.Y) }\n")
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		wantMod string
+		want    map[string][]byte
+	}{
+		{
+			name: "module named after the project",
+			files: map[string][]byte{
+				"go.mod":     []byte("module old\n\ngo 1.23\n"),
+				"main.go":    main("old/internal/x"),
+				"x/other.go": []byte("package x\n"),
+			},
+			wantMod: "new",
+			want: map[string][]byte{
+				"go.mod":  []byte("module new\n\ngo 1.23\n"),
+				"main.go": main("new/internal/x"),
+			},
+		},
+		{
+			name: "module under a prefix",
+			files: map[string][]byte{
+				"go.mod":  []byte("module github.com/me/old\n\ngo 1.23\n"),
+				"main.go": main("github.com/me/old"),
+			},
+			wantMod: "github.com/me/new",
+			want: map[string][]byte{
+				"go.mod":  []byte("module github.com/me/new\n\ngo 1.23\n"),
+				"main.go": main("github.com/me/new"),
+			},
+		},
+		{
+			name: "similar imports are left alone",
+			files: map[string][]byte{
+				"go.mod":  []byte("module old\n\ngo 1.23\n"),
+				"main.go": main("older/x"),
+			},
+			wantMod: "new",
+			want: map[string][]byte{
+				"go.mod": []byte("module new\n\ngo 1.23\n"),
+			},
+		},
+		{
+			name: "files other than Go sources are left alone",
+			files: map[string][]byte{
+				"go.mod":    []byte("module old\n\ngo 1.23\n"),
+				"NOTES.md":  []byte("# Notes\nimport \"old/x\"\n"),
+				"README.md": []byte("old\n"),
+			},
+			wantMod: "new",
+			want: map[string][]byte{
+				"go.mod": []byte("module new\n\ngo 1.23\n"),
+			},
+		},
+		{
+			name: "custom module path",
+			files: map[string][]byte{
+				"go.mod":  []byte("module example.com/custom\n\ngo 1.23\n"),
+				"main.go": main("example.com/custom/x"),
+			},
+		},
+		{
+			name:  "no go.mod",
+			files: map[string][]byte{"main.go": main("old/x")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mod, changed, err := moduleRenameChanges(tt.files, "old", "new")
+			if err != nil {
+				t.Fatalf("moduleRenameChanges failed: %v", err)
+			}
+			if mod != tt.wantMod {
+				t.Errorf("module = %q, want %q", mod, tt.wantMod)
+			}
+			if len(changed) != len(tt.want) {
+				t.Errorf("changed %d files, want %d", len(changed), len(tt.want))
+			}
+			for name, want := range tt.want {
+				if got := changed[name]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s =\n%s\nwant\n%s", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRewriteModuleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string][]byte
+	}{
+		{"invalid go.mod", map[string][]byte{"go.mod": []byte("module old\nrequire (\n")}},
+		{"invalid Go file", map[string][]byte{"main.go": []byte("package main\nimport \"old\n")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := rewriteModule(tt.files, "old", "new"); err == nil {
+				t.Error("rewriteModule succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// maxNameLength is the longest project name allowed
+const maxNameLength = 64
+
+// validName matches a project name: lowercase letters, digits, '.', '_'
+// and '-', starting with a letter or digit so it can never be a hidden
+// directory, a flag or a path such as ..
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidateName checks that name can be used for a new project: it must be
+// a single safe directory name and a valid module path
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name is required")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("project name %q is too long: use at most %d characters", name, maxNameLength)
+	}
+	if !validName.MatchString(name) {
+		return invalidName(name, "use lowercase letters, digits, '.', '_' and '-', starting with a letter or digit")
+	}
+	if err := module.CheckImportPath(name); err != nil {
+		return invalidName(name, "it is not a valid module path")
+	}
+	return nil
+}
+
+// checkName checks that name can refer to an existing project: a single
+// directory in the workspace that is not hidden. Unlike ValidateName it
+// accepts projects named before names were validated.
+func checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("project name is required")
+	}
+	if strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) || filepath.VolumeName(name) != "" {
+		return fmt.Errorf("invalid project name %q: it must be a single directory name", name)
+	}
+	return nil
+}
+
+// invalidName describes why name was rejected, suggesting its slug when
+// that would be valid
+func invalidName(name, why string) error {
+	if slug := Slugify(name); slug != "" && slug != name && ValidateName(slug) == nil {
+		return fmt.Errorf("invalid project name %q: %s (try %q)", name, why, slug)
+	}
+	return fmt.Errorf("invalid project name %q: %s", name, why)
+}
+
+// Slugify turns arbitrary text into a project name: lowercase, with runs of
+// other characters replaced by '-', e.g. "My API test!" becomes
+// "my-api-test". It may return an empty string.
+func Slugify(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+
+	slug := b.String()
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	for strings.Contains(slug, "..") {
+		slug = strings.ReplaceAll(slug, "..", ".")
+	}
+	slug = strings.Trim(slug, "-._")
+	if len(slug) > maxNameLength {
+		slug = strings.TrimRight(slug[:maxNameLength], "-._")
+	}
+	return slug
+}
+
+// NameTaken reports whether name is used by a project in s, including
+// scratch projects, which List hides, and projects whose metadata cannot
+// be read
+func NameTaken(s Store, name string) bool {
+	_, err := s.Get(name)
+	return !errors.Is(err, ErrNotFound)
+}
+
+// UniqueName returns name if it is free, or otherwise name-2, name-3 and so
+// on, whichever is free first
+func UniqueName(name string, exists func(string) bool) string {
+	if !exists(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+var (
+	nameAdjectives = []string{
+		"amber", "bold", "brave", "brisk", "calm", "clever", "cosmic", "crisp",
+		"curious", "dapper", "eager", "fancy", "fuzzy", "gentle", "glad", "golden",
+		"happy", "hidden", "jolly", "keen", "lively", "lucky", "mellow", "misty",
+		"nimble", "quiet", "rapid", "rusty", "shiny", "silent", "snappy", "sunny",
+		"swift", "tidy", "vivid", "witty", "young", "zesty",
+	}
+	nameNouns = []string{
+		"badger", "beacon", "canyon", "comet", "cricket", "dolphin", "ember", "falcon",
+		"fern", "gopher", "harbor", "heron", "island", "lantern", "maple", "meadow",
+		"meteor", "otter", "panda", "pebble", "pine", "puffin", "quokka", "raven",
+		"river", "rocket", "sparrow", "spruce", "summit", "thunder", "tiger", "valley",
+		"walrus", "willow", "wombat", "yak", "zephyr",
+	}
+)
+
+// GenerateName returns a memorable adjective-noun name, such as
+// "brave-otter", that is not taken
+func GenerateName(exists func(string) bool) string {
+	var name string
+	for range 20 {
+		name = nameAdjectives[rand.IntN(len(nameAdjectives))] + "-" + nameNouns[rand.IntN(len(nameNouns))]
+		if !exists(name) {
+			return name
+		}
+	}
+	// The workspace is crowded; number the last pick instead
+	return UniqueName(name, exists)
+}
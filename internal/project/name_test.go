@@ -0,0 +1,149 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string
+	}{
+		{"api", ""},
+		{"my-api_v2.1", ""},
+		{"9lives", ""},
+		{strings.Repeat("a", maxNameLength), ""},
+		{"", "project name is required"},
+		{strings.Repeat("a", maxNameLength+1), "too long"},
+		{"MyAPI", `(try "myapi")`},
+		{"my api", `(try "my-api")`},
+		{".hidden", "starting with a letter or digit"},
+		{"-flag", "starting with a letter or digit"},
+		{"..", "starting with a letter or digit"},
+		{"a/b", "use lowercase letters"},
+		{"con", "not a valid module path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateName(%q) = %v, want nil", tt.name, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateName(%q) = %v, want an error containing %q", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"My API test!", "my-api-test"},
+		{"already-a-slug", "already-a-slug"},
+		{"  spaced  out  ", "spaced-out"},
+		{"v1..2", "v1.2"},
+		{"--._trim_.--", "trim"},
+		{"Ünïcode", "n-code"},
+		{"!!!", ""},
+		{"", ""},
+		{strings.Repeat("ab-", 30), strings.TrimRight(strings.Repeat("ab-", 30)[:maxNameLength], "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"api", false},
+		// Projects named before names were validated can still be used
+		{"My Project", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{".goshed", true},
+		{"../x", true},
+		{"a/b", true},
+		{`a\b`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("checkName(%q) = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFSStoreRejectsPaths(t *testing.T) {
+	s := NewFSStore(t.TempDir(), t.TempDir())
+	outside := filepath.Join(filepath.Dir(s.Root()), "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	name := "../" + filepath.Base(outside)
+
+	if _, err := s.Get(name); err == nil {
+		t.Error("Get succeeded for a path")
+	}
+	if err := s.Remove(name, "test"); err == nil {
+		t.Error("Remove succeeded for a path")
+	}
+	if err := s.Archive(name); err == nil {
+		t.Error("Archive succeeded for a path")
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("directory outside the workspace is gone: %v", err)
+	}
+}
+
+func TestNameTaken(t *testing.T) {
+	s := NewFSStore(t.TempDir(), t.TempDir())
+	for _, p := range []*model.Project{
+		{Name: "api", Template: "basic"},
+		{Name: "scratch", Template: "basic", Scratch: true},
+		{Name: "broken", Template: "basic"},
+	} {
+		if err := s.Create(p); err != nil {
+			t.Fatalf("Create(%s) failed: %v", p.Name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(s.Root(), "broken", ".goshed.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("failed to write metadata: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"api", true},
+		{"scratch", true},
+		{"broken", true},
+		{"free", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameTaken(s, tt.name); got != tt.want {
+				t.Errorf("NameTaken(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// Notebook is implemented by stores that keep a journal of notes with
+// markdown bodies for each project
+type Notebook interface {
+	// NoteBodies returns the markdown bodies of a project's notes, keyed
+	// by note ID
+	NoteBodies(name string) (map[int]string, error)
+	// AddNote appends a timestamped note, with an optional markdown body,
+	// to a project's journal
+	AddNote(name, text, body string) (*model.Note, error)
+	// EditNote replaces the text and body of a note
+	EditNote(name string, id int, text, body string) (*model.Note, error)
+	// DeleteNote removes a note and its body
+	DeleteNote(name string, id int) error
+}
+
+// notesFile holds the markdown bodies of a project's notes
+const notesFile = "NOTES.md"
+
+// noteMarker starts the body of a note in notesFile. It is an HTML comment
+// so that the file still renders as plain markdown.
+var noteMarker = regexp.MustCompile(`^<!-- note (\d+) -->$`)
+
+// escapedMarker matches a body line that would read as a noteMarker,
+// possibly already escaped. formatNotes adds a backslash in front of such
+// lines and parseNotes removes it again.
+var escapedMarker = regexp.MustCompile(`^\\*<!-- note \d+ -->$`)
+
+// formatNotes renders the bodies of the given notes as a markdown file.
+// Notes without a body are left out.
+func formatNotes(notes []model.Note, bodies map[int]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Notes\n")
+	for _, n := range notes {
+		body, ok := bodies[n.ID]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n<!-- note %d -->\n", n.ID)
+		fmt.Fprintf(&buf, "## %s · %s\n\n", n.Created.Format("2006-01-02 15:04"), n.Text)
+		for _, line := range strings.Split(body, "\n") {
+			if escapedMarker.MatchString(line) {
+				buf.WriteString(`\`)
+			}
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+// parseNotes returns the note bodies in a notes file, keyed by note ID.
+// The heading under each marker is generated and is not part of the body.
+func parseNotes(data []byte) (map[int]string, error) {
+	bodies := make(map[int]string)
+
+	id := 0
+	var body strings.Builder
+	flush := func() {
+		if id != 0 {
+			if text := strings.TrimSpace(body.String()); text != "" {
+				bodies[id] = text
+			}
+		}
+		body.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// A single line may be as long as the whole file
+	scanner.Buffer(nil, max(len(data)+1, bufio.MaxScanTokenSize))
+	heading := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := noteMarker.FindStringSubmatch(line); m != nil {
+			flush()
+			id, _ = strconv.Atoi(m[1])
+			heading = true
+			continue
+		}
+		if heading && strings.HasPrefix(line, "## ") {
+			heading = false
+			continue
+		}
+		heading = false
+		if escapedMarker.MatchString(line) {
+			line = line[1:]
+		}
+		if id != 0 {
+			body.WriteString(line)
+			body.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse notes: %w", err)
+	}
+	flush()
+
+	return bodies, nil
+}
+
+// addNote appends a note to p, giving it the next free ID
+func addNote(p *model.Project, bodies map[int]string, text, body string) (*model.Note, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("note text is required")
+	}
+
+	id := 1
+	for _, n := range p.Notes {
+		if n.ID >= id {
+			id = n.ID + 1
+		}
+	}
+
+	p.Notes = append(p.Notes, model.Note{ID: id, Created: time.Now(), Text: text})
+	setNoteBody(p, bodies, id, body)
+	return &p.Notes[len(p.Notes)-1], nil
+}
+
+// editNote replaces the text and body of a note
+func editNote(p *model.Project, bodies map[int]string, id int, text, body string) (*model.Note, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("note text is required")
+	}
+
+	i, err := findNote(p, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	p.Notes[i].Text = text
+	p.Notes[i].Edited = &now
+	setNoteBody(p, bodies, id, body)
+	return &p.Notes[i], nil
+}
+
+// deleteNote removes a note and its body
+func deleteNote(p *model.Project, bodies map[int]string, id int) error {
+	i, err := findNote(p, id)
+	if err != nil {
+		return err
+	}
+
+	p.Notes = append(p.Notes[:i], p.Notes[i+1:]...)
+	delete(bodies, id)
+	return nil
+}
+
+// setNoteBody stores the body of a note, removing it when body is empty
+func setNoteBody(p *model.Project, bodies map[int]string, id int, body string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		delete(bodies, id)
+	} else {
+		bodies[id] = body
+	}
+
+	for i := range p.Notes {
+		if p.Notes[i].ID == id {
+			p.Notes[i].HasBody = body != ""
+		}
+	}
+}
+
+func findNote(p *model.Project, id int) (int, error) {
+	for i, n := range p.Notes {
+		if n.ID == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("project %s has no note %d", p.Name, id)
+}
+
+// readNoteBodies reads the note bodies of the project in dir
+func readNoteBodies(dir string) (map[int]string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, notesFile))
+	if os.IsNotExist(err) {
+		return make(map[int]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %w", err)
+	}
+	return parseNotes(data)
+}
+
+// NoteBodies returns the markdown bodies of a project's notes, keyed by
+// note ID
+func (s *FSStore) NoteBodies(name string) (map[int]string, error) {
+	p, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return readNoteBodies(p.Path)
+}
+
+// AddNote appends a note to a project's journal
+func (s *FSStore) AddNote(name, text, body string) (*model.Note, error) {
+	var note *model.Note
+	err := s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		var err error
+		note, err = addNote(p, bodies, text, body)
+		return err
+	})
+	return note, err
+}
+
+// EditNote replaces the text and body of a note
+func (s *FSStore) EditNote(name string, id int, text, body string) (*model.Note, error) {
+	var note *model.Note
+	err := s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		var err error
+		note, err = editNote(p, bodies, id, text, body)
+		return err
+	})
+	return note, err
+}
+
+// DeleteNote removes a note from a project's journal
+func (s *FSStore) DeleteNote(name string, id int) error {
+	return s.updateNotes(name, func(p *model.Project, bodies map[int]string) error {
+		return deleteNote(p, bodies, id)
+	})
+}
+
+// updateNotes applies a change to a project's notes under its lock and
+// saves both NOTES.md and the metadata. Changing notes counts as accessing
+// the project.
+func (s *FSStore) updateNotes(name string, apply func(p *model.Project, bodies map[int]string) error) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	bodies, err := readNoteBodies(p.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := apply(p, bodies); err != nil {
+		return err
+	}
+
+	notesPath := filepath.Join(p.Path, notesFile)
+	if len(bodies) == 0 {
+		if err := os.Remove(notesPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove notes: %w", err)
+		}
+	} else if err := writeFileAtomic(notesPath, formatNotes(p.Notes, bodies), 0644); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+
+	p.LastAccessed = time.Now()
+	p.Revision++
+	if err := writeMetadata(p); err != nil {
+		return err
+	}
+
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+	return nil
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestNotesRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	notes := []model.Note{
+		{ID: 1, Created: created, Text: "first"},
+		{ID: 2, Created: created, Text: "no body"},
+		{ID: 3, Created: created, Text: "tricky"},
+		{ID: 4, Created: created, Text: "long"},
+	}
+
+	tests := []struct {
+		name   string
+		bodies map[int]string
+	}{
+		{"none", map[int]string{}},
+		{"plain", map[int]string{1: "Some *markdown*\n\n- a list"}},
+		{"heading in body", map[int]string{1: "## Not generated\ntext"}},
+		{"marker in body", map[int]string{1: "before\n<!-- note 2 -->\nafter", 3: "<!-- note 1 -->"}},
+		{"escaped marker in body", map[int]string{3: `\<!-- note 9 -->` + "\n" + `\\<!-- note 9 -->`}},
+		{"several notes", map[int]string{1: "one", 3: "three"}},
+		{"long line", map[int]string{4: strings.Repeat("x", 200_000)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := formatNotes(notes, tt.bodies)
+			got, err := parseNotes(data)
+			if err != nil {
+				t.Fatalf("parseNotes failed: %v", err)
+			}
+			if !maps.Equal(got, tt.bodies) {
+				t.Errorf("parseNotes(formatNotes(%q)) = %q\nfile:\n%s", tt.bodies, got, data)
+			}
+		})
+	}
+}
+
+func TestFormatNotes(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	notes := []model.Note{
+		{ID: 1, Created: created, Text: "first"},
+		{ID: 2, Created: created, Text: "second"},
+	}
+	got := string(formatNotes(notes, map[int]string{2: "body\n<!-- note 1 -->"}))
+	want := "# Notes\n\n<!-- note 2 -->\n## 2024-03-01 09:30 · second\n\nbody\n\\<!-- note 1 -->\n"
+	if got != want {
+		t.Errorf("formatNotes() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[int]string
+	}{
+		{"empty", "", map[int]string{}},
+		{"title only", "# Notes\n", map[int]string{}},
+		{"text before the first marker", "# Notes\nstray\n\n<!-- note 1 -->\n## heading\n\nbody\n", map[int]string{1: "body"}},
+		{"missing heading", "<!-- note 1 -->\nbody\n", map[int]string{1: "body"}},
+		{"empty body", "<!-- note 1 -->\n## heading\n\n\n<!-- note 2 -->\n## heading\nbody\n", map[int]string{2: "body"}},
+		{"windows line endings", "<!-- note 1 -->\r\n## heading\r\n\r\nbody\r\n", map[int]string{1: "body"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNotes([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseNotes failed: %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
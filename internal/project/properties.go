@@ -0,0 +1,73 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// ValidatePropertyKey checks that a property key is non-empty and only
+// uses letters, digits, '.', '_' and '-'
+func ValidatePropertyKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("property key is required")
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == '-':
+		default:
+			return fmt.Errorf("invalid property key %q: only letters, digits, '.', '_' and '-' are allowed", key)
+		}
+	}
+	return nil
+}
+
+// ParseProperty splits a key=value argument into a validated key and its
+// value
+func ParseProperty(arg string) (string, string, error) {
+	key, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid property %q: expected key=value", arg)
+	}
+	key = strings.TrimSpace(key)
+	if err := ValidatePropertyKey(key); err != nil {
+		return "", "", err
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// PropertyFilter matches projects that have a property, optionally with a
+// given value
+type PropertyFilter struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParsePropertyFilter parses "key" or "key=value"
+func ParsePropertyFilter(arg string) (PropertyFilter, error) {
+	if strings.Contains(arg, "=") {
+		key, value, err := ParseProperty(arg)
+		if err != nil {
+			return PropertyFilter{}, err
+		}
+		return PropertyFilter{Key: key, Value: value, HasValue: true}, nil
+	}
+
+	key := strings.TrimSpace(arg)
+	if err := ValidatePropertyKey(key); err != nil {
+		return PropertyFilter{}, err
+	}
+	return PropertyFilter{Key: key}, nil
+}
+
+// Match reports whether a project satisfies the filter
+func (f PropertyFilter) Match(p *model.Project) bool {
+	value, ok := p.Properties[f.Key]
+	if !ok {
+		return false
+	}
+	return !f.HasValue || value == f.Value
+}
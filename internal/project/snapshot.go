@@ -0,0 +1,753 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// snapshotRef is the hidden ref whose history holds the snapshots of a
+// project with a git repository. It is not a branch, so it never shows up
+// in git branch or moves HEAD.
+const snapshotRef = "refs/goshed/snapshots"
+
+// snapshotDir holds the compressed copies of a project without git
+const snapshotDir = ".goshed-snapshots"
+
+// snapshotExcluded are the top-level paths snapshots leave alone: goshed's
+// metadata and note bodies, which restoring would put out of step with the
+// store, and the snapshots themselves
+var snapshotExcluded = []string{".goshed.json", ".goshed.json.bak", notesFile, snapshotDir}
+
+// snapshotIdent is the author and committer of snapshot commits
+var snapshotIdent = []string{
+	"GIT_AUTHOR_NAME=goshed", "GIT_AUTHOR_EMAIL=goshed@localhost",
+	"GIT_COMMITTER_NAME=goshed", "GIT_COMMITTER_EMAIL=goshed@localhost",
+}
+
+// ErrUnsavedChanges is returned when restoring a snapshot would overwrite
+// changes that are in neither a git commit nor a snapshot
+var ErrUnsavedChanges = errors.New("the playground has changes that are not in a commit or snapshot")
+
+// Snapshot is a saved state of a project's files
+type Snapshot struct {
+	// ID is the commit of a git snapshot, or the file name of a copy
+	ID      string    `json:"id"`
+	Label   string    `json:"label,omitempty"`
+	Created time.Time `json:"created"`
+	// Hash identifies the contents: the git tree of a git snapshot, or a
+	// SHA-256 of the files of a copy
+	Hash string `json:"hash"`
+	// Git is set for snapshots stored as commits
+	Git bool `json:"-"`
+}
+
+// ShortID returns an abbreviated ID for display
+func (s *Snapshot) ShortID() string {
+	if s.Git && len(s.ID) > 12 {
+		return s.ID[:12]
+	}
+	return s.ID
+}
+
+// SaveSnapshot records the current state of a project's files. Projects
+// with a git repository get a commit on a hidden ref; others get a
+// compressed copy in .goshed-snapshots.
+func (s *FSStore) SaveSnapshot(name, label string) (*Snapshot, error) {
+	p, unlock, err := s.getLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return saveSnapshot(p, label)
+}
+
+// ListSnapshots returns a project's snapshots, newest first
+func (s *FSStore) ListSnapshots(name string) ([]*Snapshot, error) {
+	p, unlock, err := s.getLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return listSnapshots(p)
+}
+
+// RestoreSnapshot puts a project's files back as they were in the snapshot
+// picked by ref. Files created since are removed. Unless force is set it
+// fails with ErrUnsavedChanges rather than lose changes that are not in a
+// commit or another snapshot.
+func (s *FSStore) RestoreSnapshot(name, ref string, force bool) (*Snapshot, error) {
+	p, unlock, err := s.getLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return restoreSnapshot(p, ref, force)
+}
+
+// DiffSnapshot writes the differences between the snapshot picked by ref
+// and the project's current files to w, as a patch or, with stat, a
+// summary. It reports whether there were any differences.
+func (s *FSStore) DiffSnapshot(name, ref string, stat bool, w io.Writer) (*Snapshot, bool, error) {
+	p, unlock, err := s.getLocked(name)
+	if err != nil {
+		return nil, false, err
+	}
+	defer unlock()
+	return diffSnapshot(p, ref, stat, w)
+}
+
+// getLocked takes a project's lock and reads it. The returned function
+// releases the lock.
+func (s *FSStore) getLocked(name string) (*model.Project, func(), error) {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	p, err := s.Get(name)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return p, unlock, nil
+}
+
+// saveSnapshot records the current state of a project's files
+func saveSnapshot(p *model.Project, label string) (*Snapshot, error) {
+	if p.Path == "" {
+		return nil, fmt.Errorf("project %s has no directory to snapshot", p.Name)
+	}
+	if strings.ContainsAny(label, "\r\n") {
+		return nil, fmt.Errorf("snapshot label must be a single line")
+	}
+	if hasGit(p.Path) {
+		return saveGitSnapshot(p.Path, label)
+	}
+	return saveCopySnapshot(p.Path, label)
+}
+
+// listSnapshots returns a project's snapshots, newest first
+func listSnapshots(p *model.Project) ([]*Snapshot, error) {
+	if p.Path == "" {
+		return nil, nil
+	}
+
+	var snapshots []*Snapshot
+	if hasGit(p.Path) {
+		gitSnapshots, err := listGitSnapshots(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, gitSnapshots...)
+	}
+	// Copies made before the project had git are still listed
+	copies, err := listCopySnapshots(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	snapshots = append(snapshots, copies...)
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+	return snapshots, nil
+}
+
+// FindSnapshot picks a snapshot by ID, ID prefix or label. An empty ref
+// picks the newest snapshot, and a label used more than once its newest.
+func FindSnapshot(snapshots []*Snapshot, ref string) (*Snapshot, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("there are no snapshots")
+	}
+	if ref == "" {
+		return snapshots[0], nil
+	}
+
+	for _, s := range snapshots {
+		if s.ID == ref || s.ShortID() == ref {
+			return s, nil
+		}
+	}
+	for _, s := range snapshots {
+		if s.Label == ref {
+			return s, nil
+		}
+	}
+
+	var found *Snapshot
+	for _, s := range snapshots {
+		if len(ref) >= 4 && strings.HasPrefix(s.ID, ref) {
+			if found != nil {
+				return nil, fmt.Errorf("snapshot %q is ambiguous", ref)
+			}
+			found = s
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no snapshot has ID or label %q", ref)
+	}
+	return found, nil
+}
+
+// restoreSnapshot puts a project's files back as they were in the snapshot
+// picked by ref
+func restoreSnapshot(p *model.Project, ref string, force bool) (*Snapshot, error) {
+	snapshots, err := listSnapshots(p)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := FindSnapshot(snapshots, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		unsaved, err := unsavedChanges(p.Path, snapshots)
+		if err != nil {
+			return nil, err
+		}
+		if unsaved {
+			return nil, ErrUnsavedChanges
+		}
+	}
+
+	if snap.Git {
+		err = restoreGitSnapshot(p.Path, snap)
+	} else {
+		err = restoreCopySnapshot(p.Path, snap)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot %s: %w", snap.ShortID(), err)
+	}
+	return snap, nil
+}
+
+// diffSnapshot writes the differences between the snapshot picked by ref
+// and the project's current files to w
+func diffSnapshot(p *model.Project, ref string, stat bool, w io.Writer) (*Snapshot, bool, error) {
+	snapshots, err := listSnapshots(p)
+	if err != nil {
+		return nil, false, err
+	}
+	snap, err := FindSnapshot(snapshots, ref)
+	if err != nil {
+		return nil, false, err
+	}
+
+	args := []string{"--no-pager", "diff", "--exit-code"}
+	if stat {
+		args = append(args, "--stat")
+	}
+
+	var cmd *exec.Cmd
+	if snap.Git {
+		env, cleanup, err := tempIndex()
+		if err != nil {
+			return nil, false, err
+		}
+		defer cleanup()
+		tree, err := worktreeTree(p.Path, env)
+		if err != nil {
+			return nil, false, err
+		}
+		cmd = exec.Command("git", append([]string{"-C", p.Path}, append(args,
+			"--src-prefix=snapshot/", "--dst-prefix=working/", snap.ID, tree)...)...)
+	} else {
+		// Compare the copy with the current files in a throwaway
+		// repository, so the patch looks the same as for git snapshots
+		tmp, err := os.MkdirTemp("", "goshed-diff-")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		if err := extractCopy(p.Path, snap, filepath.Join(tmp, "snapshot")); err != nil {
+			return nil, false, err
+		}
+		repo := filepath.Join(tmp, "repo")
+		if _, err := runGit(tmp, nil, "init", "-q", "--bare", repo); err != nil {
+			return nil, false, err
+		}
+		from, err := copyTree(repo, filepath.Join(tmp, "snapshot"))
+		if err != nil {
+			return nil, false, err
+		}
+		to, err := copyTree(repo, p.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		cmd = exec.Command("git", append([]string{"--git-dir", repo}, append(args,
+			"--src-prefix=snapshot/", "--dst-prefix=working/", from, to)...)...)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return snap, false, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return snap, true, nil
+	default:
+		return nil, false, fmt.Errorf("failed to diff snapshot: %s", gitErrorText(err, &stderr))
+	}
+}
+
+// hasGit reports whether dir has a git repository
+func hasGit(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// runGit runs a git command in dir with extra environment variables and
+// returns its output
+func runGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], gitErrorText(err, &stderr))
+	}
+	return string(out), nil
+}
+
+// gitErrorText describes a failed git command by its error output
+func gitErrorText(err error, stderr *bytes.Buffer) string {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return msg
+	}
+	return err.Error()
+}
+
+// tempIndex returns the environment for git commands to use a scratch
+// index, which leaves the project's own index and HEAD untouched
+func tempIndex() ([]string, func(), error) {
+	dir, err := os.MkdirTemp("", "goshed-index-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+	return env, func() { os.RemoveAll(dir) }, nil
+}
+
+// worktreeTree stages every file that is not ignored or excluded from
+// snapshots into the index in env and writes it as a tree
+func worktreeTree(dir string, env []string) (string, error) {
+	return stageTree(dir, env, "add", "-A")
+}
+
+// copyTree writes the files a compressed copy of dir would hold, ignored
+// ones included, as a tree in the repository at gitDir
+func copyTree(gitDir, dir string) (string, error) {
+	env, cleanup, err := tempIndex()
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	env = append(env, "GIT_DIR="+gitDir, "GIT_WORK_TREE="+dir)
+	return stageTree(dir, env, "add", "-A", "--force")
+}
+
+// stageTree stages dir's files with the given add command, unstages the
+// paths excluded from snapshots and writes the index as a tree
+func stageTree(dir string, env []string, add ...string) (string, error) {
+	if _, err := runGit(dir, env, add...); err != nil {
+		return "", err
+	}
+	args := append([]string{"rm", "--cached", "-r", "-q", "--ignore-unmatch", "--"}, snapshotExcluded...)
+	if _, err := runGit(dir, env, args...); err != nil {
+		return "", err
+	}
+	tree, err := runGit(dir, env, "write-tree")
+	return strings.TrimSpace(tree), err
+}
+
+func saveGitSnapshot(dir, label string) (*Snapshot, error) {
+	env, cleanup, err := tempIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	tree, err := worktreeTree(dir, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	// Each snapshot's parent is the previous one, so the ref's history is
+	// the list of snapshots
+	args := []string{"commit-tree", tree, "-m", label}
+	parent, err := runGit(dir, nil, "rev-parse", "-q", "--verify", snapshotRef)
+	parent = strings.TrimSpace(parent)
+	if err == nil {
+		args = append(args, "-p", parent)
+	}
+	commit, err := runGit(dir, snapshotIdent, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	commit = strings.TrimSpace(commit)
+	if _, err := runGit(dir, nil, "update-ref", snapshotRef, commit, parent); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return &Snapshot{ID: commit, Label: label, Created: time.Now(), Hash: tree, Git: true}, nil
+}
+
+func listGitSnapshots(dir string) ([]*Snapshot, error) {
+	if _, err := runGit(dir, nil, "rev-parse", "-q", "--verify", snapshotRef); err != nil {
+		return nil, nil
+	}
+	out, err := runGit(dir, nil, "log", "--format=%H%x1f%T%x1f%ct%x1f%s", snapshotRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		secs, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, &Snapshot{
+			ID:      fields[0],
+			Hash:    fields[1],
+			Created: time.Unix(secs, 0),
+			Label:   fields[3],
+			Git:     true,
+		})
+	}
+	return snapshots, nil
+}
+
+func restoreGitSnapshot(dir string, snap *Snapshot) error {
+	env, cleanup, err := tempIndex()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := worktreeTree(dir, env); err != nil {
+		return err
+	}
+	current, err := runGit(dir, env, "ls-files", "-z")
+	if err != nil {
+		return err
+	}
+	if _, err := runGit(dir, env, "read-tree", snap.ID); err != nil {
+		return err
+	}
+	wanted, err := runGit(dir, env, "ls-files", "-z")
+	if err != nil {
+		return err
+	}
+
+	keep := strings.Split(wanted, "\x00")
+	var removed []string
+	for _, file := range strings.Split(current, "\x00") {
+		if file == "" || slices.Contains(keep, file) {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed = append(removed, path)
+	}
+	if _, err := runGit(dir, env, "checkout-index", "-a", "-f"); err != nil {
+		return err
+	}
+	removeEmptyParents(dir, removed)
+	return nil
+}
+
+// skipForCopy leaves .git and the paths excluded from snapshots out of
+// compressed copies
+func skipForCopy(rel string) bool {
+	return rel == ".git" || slices.Contains(snapshotExcluded, rel)
+}
+
+func (s *Snapshot) copyPath(dir string) string {
+	return filepath.Join(dir, snapshotDir, s.ID+".tar.gz")
+}
+
+func saveCopySnapshot(dir, label string) (*Snapshot, error) {
+	hash, err := copyHash(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, snapshotDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	now := time.Now()
+	snap := &Snapshot{Label: label, Created: now, Hash: hash}
+	snap.ID = UniqueName(now.Format("20060102-150405"), func(id string) bool {
+		_, err := os.Stat(filepath.Join(dir, snapshotDir, id+".json"))
+		return err == nil
+	})
+
+	// Write the copy under a temporary name so a failure never leaves a
+	// truncated copy behind
+	tmp, err := os.CreateTemp(filepath.Join(dir, snapshotDir), "."+snap.ID+".tar.gz.tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if err := writeTarGz(tmp, dir, skipForCopy); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, snap.copyPath(dir)); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, snapshotDir, snap.ID+".json"), data, 0644); err != nil {
+		os.Remove(snap.copyPath(dir))
+		return nil, fmt.Errorf("failed to write snapshot metadata: %w", err)
+	}
+	return snap, nil
+}
+
+func listCopySnapshots(dir string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, snapshotDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, snapshotDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil || snap.ID == "" {
+			continue
+		}
+		snapshots = append(snapshots, &snap)
+	}
+	return snapshots, nil
+}
+
+// extractCopy unpacks a compressed copy into dest
+func extractCopy(dir string, snap *Snapshot, dest string) error {
+	f, err := os.Open(snap.copyPath(dir))
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+	if err := extractTarGz(f, dest); err != nil {
+		return fmt.Errorf("failed to unpack snapshot: %w", err)
+	}
+	return nil
+}
+
+// restoreCopySnapshot unpacks a compressed copy next to the project first,
+// so that a failure leaves the project untouched, and then swaps the
+// unpacked files in for the current ones
+func restoreCopySnapshot(dir string, snap *Snapshot) error {
+	staging, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	unpacked := filepath.Join(staging, "snapshot")
+	aside := filepath.Join(staging, "current")
+	if err := os.Mkdir(aside, 0755); err != nil {
+		return err
+	}
+	if err := extractCopy(dir, snap, unpacked); err != nil {
+		return err
+	}
+
+	current, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	restored, err := os.ReadDir(unpacked)
+	if err != nil {
+		return err
+	}
+
+	// rollback puts the current files back in place of the restored ones
+	var moved, placed []string
+	rollback := func() {
+		for _, name := range placed {
+			os.RemoveAll(filepath.Join(dir, name))
+		}
+		for _, name := range moved {
+			os.Rename(filepath.Join(aside, name), filepath.Join(dir, name))
+		}
+	}
+
+	for _, entry := range current {
+		if skipForCopy(entry.Name()) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, entry.Name()), filepath.Join(aside, entry.Name())); err != nil {
+			rollback()
+			return err
+		}
+		moved = append(moved, entry.Name())
+	}
+	for _, entry := range restored {
+		if skipForCopy(entry.Name()) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(unpacked, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			rollback()
+			return err
+		}
+		placed = append(placed, entry.Name())
+	}
+	return nil
+}
+
+// copyHash hashes the paths, permissions and contents of the files a
+// compressed copy of dir would hold
+func copyHash(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != "." && skipForCopy(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "link %s %s\x00", rel, link)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "file %s %o\x00", rel, info.Mode().Perm())
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// unsavedChanges reports whether a project's files differ from its latest
+// git commit and from every snapshot
+func unsavedChanges(dir string, snapshots []*Snapshot) (bool, error) {
+	if hasGit(dir) {
+		args := []string{"status", "--porcelain", "--", "."}
+		for _, path := range snapshotExcluded {
+			args = append(args, ":(exclude)"+path)
+		}
+		status, err := runGit(dir, nil, args...)
+		if err != nil {
+			return false, err
+		}
+		if strings.TrimSpace(status) == "" {
+			return false, nil
+		}
+
+		env, cleanup, err := tempIndex()
+		if err != nil {
+			return false, err
+		}
+		defer cleanup()
+		tree, err := worktreeTree(dir, env)
+		if err != nil {
+			return false, err
+		}
+		for _, s := range snapshots {
+			if s.Git && s.Hash == tree {
+				return false, nil
+			}
+		}
+	}
+
+	var hash string
+	for _, s := range snapshots {
+		if s.Git {
+			continue
+		}
+		if hash == "" {
+			var err error
+			if hash, err = copyHash(dir); err != nil {
+				return false, err
+			}
+		}
+		if s.Hash == hash {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// removeEmptyParents removes the directories under dir that removing the
+// given files left empty. Directories that were already empty are kept.
+func removeEmptyParents(dir string, removed []string) {
+	for _, path := range removed {
+		for parent := filepath.Dir(path); parent != dir && isWithin(dir, parent); parent = filepath.Dir(parent) {
+			// Remove fails, and the walk stops, at the first directory
+			// that still has entries
+			if os.Remove(parent) != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+func TestFSStoreSnapshots(t *testing.T) {
+	tests := []struct {
+		name string
+		git  bool
+	}{
+		{"git", true},
+		{"copy", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewFSStore(t.TempDir(), t.TempDir())
+			p := mustCreate(t, s, &model.Project{Name: "api"})
+			if !tt.git {
+				if err := os.RemoveAll(filepath.Join(p.Path, ".git")); err != nil {
+					t.Fatalf("failed to remove .git: %v", err)
+				}
+			} else if !hasGit(p.Path) {
+				t.Skip("git is not available")
+			}
+
+			snap, err := s.SaveSnapshot("api", "base")
+			if err != nil {
+				t.Fatalf("SaveSnapshot failed: %v", err)
+			}
+			if snap.Git != tt.git {
+				t.Errorf("snapshot Git = %v, want %v", snap.Git, tt.git)
+			}
+
+			mainFile := filepath.Join(p.Path, "main.go")
+			original, err := os.ReadFile(mainFile)
+			if err != nil {
+				t.Fatalf("failed to read main.go: %v", err)
+			}
+			if err := os.WriteFile(mainFile, []byte("package main\n"), 0644); err != nil {
+				t.Fatalf("failed to write main.go: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(p.Path, "new.go"), []byte("package main\n"), 0644); err != nil {
+				t.Fatalf("failed to write new.go: %v", err)
+			}
+
+			if _, err := s.RestoreSnapshot("api", "base", false); !errors.Is(err, ErrUnsavedChanges) {
+				t.Fatalf("RestoreSnapshot without force = %v, want ErrUnsavedChanges", err)
+			}
+			if _, err := s.RestoreSnapshot("api", "base", true); err != nil {
+				t.Fatalf("RestoreSnapshot failed: %v", err)
+			}
+			if got, _ := os.ReadFile(mainFile); string(got) != string(original) {
+				t.Errorf("main.go = %q after restore, want %q", got, original)
+			}
+			if _, err := os.Stat(filepath.Join(p.Path, "new.go")); !os.IsNotExist(err) {
+				t.Error("new.go survived the restore")
+			}
+			if _, err := os.Stat(filepath.Join(p.Path, ".goshed.json")); err != nil {
+				t.Errorf("metadata is gone after the restore: %v", err)
+			}
+
+			snapshots, err := s.ListSnapshots("api")
+			if err != nil {
+				t.Fatalf("ListSnapshots failed: %v", err)
+			}
+			if len(snapshots) != 1 || snapshots[0].Label != "base" {
+				t.Errorf("ListSnapshots = %+v, want the base snapshot", snapshots)
+			}
+
+			if _, err := s.SaveSnapshot("../api", ""); err == nil {
+				t.Error("SaveSnapshot succeeded for a path")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/template"
+)
+
+// Store persists playgrounds and their metadata. FSStore keeps them on
+// disk; MemoryStore keeps them in memory for tests and embedding. Features
+// beyond the basics are optional interfaces a store may also implement,
+// such as Renamer, Forker, Snapshotter, Archiver, Trasher, Adopter and
+// Notebook.
+type Store interface {
+	// Create creates a new project from its template
+	Create(p *model.Project) error
+	// Get retrieves a project by name
+	Get(name string) (*model.Project, error)
+	// Update saves a project's metadata, failing with ErrConflict if it
+	// changed since p was read
+	Update(p *model.Project) error
+	// Remove moves a project into the trash, recording why it was removed.
+	// Stores that are not Trashers delete it.
+	Remove(name, reason string) error
+	// List returns all projects in the store except unkept scratch
+	// projects, sorted by name
+	List() ([]*model.Project, error)
+	// CopyTo copies a project's files, without metadata, note bodies or
+	// snapshots, to a directory
+	CopyTo(p *model.Project, dest string) error
+}
+
+// Renamer is implemented by stores that can rename projects
+type Renamer interface {
+	// Rename renames a project along with its generated module path, and
+	// points its forks at the new name. It fails if a project with the new
+	// name already exists.
+	Rename(oldName, newName string) (*model.Project, error)
+}
+
+// Forker is implemented by stores that can fork projects
+type Forker interface {
+	// Fork creates a new project from a copy of the source project, with
+	// fresh timestamps and its module path rewritten for the new name.
+	// Notes are only copied when keepNotes is set.
+	Fork(source, name string, keepNotes bool) (*model.Project, error)
+}
+
+// Snapshotter is implemented by stores that can checkpoint a project's
+// files and roll them back
+type Snapshotter interface {
+	// SaveSnapshot records the current state of a project's files under an
+	// optional label
+	SaveSnapshot(name, label string) (*Snapshot, error)
+	// ListSnapshots returns a project's snapshots, newest first
+	ListSnapshots(name string) ([]*Snapshot, error)
+	// RestoreSnapshot puts a project's files back as they were in the
+	// snapshot picked by ref, an ID, ID prefix or label, or the newest one
+	// if ref is empty. Unless force is set it fails with ErrUnsavedChanges
+	// rather than lose changes that are not in a commit or snapshot.
+	RestoreSnapshot(name, ref string, force bool) (*Snapshot, error)
+	// DiffSnapshot writes the differences between the snapshot picked by
+	// ref and the project's current files to w, as a patch or, with stat,
+	// a summary. It reports whether there were any differences.
+	DiffSnapshot(name, ref string, stat bool, w io.Writer) (*Snapshot, bool, error)
+}
+
+var (
+	_ Store = (*FSStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+
+	_ Renamer  = (*FSStore)(nil)
+	_ Renamer  = (*MemoryStore)(nil)
+	_ Forker   = (*FSStore)(nil)
+	_ Forker   = (*MemoryStore)(nil)
+	_ Archiver = (*FSStore)(nil)
+	_ Archiver = (*MemoryStore)(nil)
+	_ Trasher  = (*FSStore)(nil)
+	_ Trasher  = (*MemoryStore)(nil)
+	_ Adopter  = (*FSStore)(nil)
+	_ Adopter  = (*MemoryStore)(nil)
+	_ Notebook = (*FSStore)(nil)
+	_ Notebook = (*MemoryStore)(nil)
+
+	_ Snapshotter     = (*FSStore)(nil)
+	_ Migrator        = (*FSStore)(nil)
+	_ Indexer         = (*FSStore)(nil)
+	_ WorkspaceLocker = (*FSStore)(nil)
+	_ Doctor          = (*FSStore)(nil)
+)
+
+// ErrNotFound is returned when a project does not exist
+var ErrNotFound = errors.New("does not exist")
+
+// ErrConflict is returned by Update when the project was saved by someone
+// else after it was read
+var ErrConflict = errors.New("project was modified by another process; reload it and try again")
+
+// projectFiles returns the initial files of a new project, its go.mod and
+// the files of its template, along with the permissions of the files that
+// are not 0644
+func projectFiles(p *model.Project) (map[string][]byte, map[string]os.FileMode, error) {
+	tmpl, err := template.Get(p.Template)
+	if errors.Is(err, template.ErrNotFound) {
+		// Fallback to basic template if specified template not found
+		tmpl, err = template.Get("basic")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	goMod, err := goModFile(p.Module, p.GoVersion, p.Toolchain)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := map[string][]byte{
+		"go.mod": goMod,
+	}
+	for filename, content := range tmpl.Files {
+		files[filename] = []byte(content)
+	}
+
+	return files, tmpl.Modes, nil
+}
+
+// SortPinnedFirst moves pinned projects ahead of the others, keeping the
+// existing order within each group
+func SortPinnedFirst(projects []*model.Project) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Pinned && !projects[j].Pinned
+	})
+}
@@ -0,0 +1,417 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// contractStore is the part of a store the contract tests exercise
+type contractStore interface {
+	Store
+	Renamer
+	Forker
+	Trasher
+	Notebook
+}
+
+// stores returns a fresh instance of every Store implementation
+func stores(t *testing.T) map[string]contractStore {
+	return map[string]contractStore{
+		"memory": NewMemoryStore(),
+		"fs":     NewFSStore(t.TempDir(), t.TempDir()),
+	}
+}
+
+// runContract runs a test against every Store implementation
+func runContract(t *testing.T, test func(t *testing.T, s contractStore)) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			test(t, s)
+		})
+	}
+}
+
+// mustCreate creates a basic project or fails the test
+func mustCreate(t *testing.T, s Store, p *model.Project) *model.Project {
+	t.Helper()
+	if p.Template == "" {
+		p.Template = "basic"
+	}
+	if p.Created.IsZero() {
+		p.Created = time.Now()
+		p.LastAccessed = p.Created
+	}
+	if err := s.Create(p); err != nil {
+		t.Fatalf("Create(%s) failed: %v", p.Name, err)
+	}
+	got, err := s.Get(p.Name)
+	if err != nil {
+		t.Fatalf("Get(%s) failed: %v", p.Name, err)
+	}
+	return got
+}
+
+// names returns the names of projects, in order
+func names(projects []*model.Project) []string {
+	var out []string
+	for _, p := range projects {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+func TestStoreCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		project model.Project
+		wantErr string
+		check   func(t *testing.T, p *model.Project)
+	}{
+		{
+			name:    "defaults",
+			project: model.Project{Name: "api", Tags: []string{" Web", "web", "CLI"}},
+			check: func(t *testing.T, p *model.Project) {
+				if p.Module != "api" || p.GoVersion != DefaultGoVersion {
+					t.Errorf("module %q go %q, want api and %s", p.Module, p.GoVersion, DefaultGoVersion)
+				}
+				if !slices.Equal(p.Tags, []string{"web", "cli"}) {
+					t.Errorf("Tags = %q, want [web cli]", p.Tags)
+				}
+				if p.SchemaVersion != model.SchemaVersion {
+					t.Errorf("SchemaVersion = %d, want %d", p.SchemaVersion, model.SchemaVersion)
+				}
+			},
+		},
+		{
+			name:    "custom module",
+			project: model.Project{Name: "api", Module: "example.com/me/api"},
+			check: func(t *testing.T, p *model.Project) {
+				if p.Module != "example.com/me/api" {
+					t.Errorf("Module = %q, want example.com/me/api", p.Module)
+				}
+			},
+		},
+		{
+			name:    "unknown template falls back to basic",
+			project: model.Project{Name: "api", Template: "nope"},
+		},
+		{
+			name:    "invalid name",
+			project: model.Project{Name: "My API"},
+			wantErr: "invalid project name",
+		},
+		{
+			name:    "invalid module",
+			project: model.Project{Name: "api", Module: "not a module"},
+			wantErr: "module",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runContract(t, func(t *testing.T, s contractStore) {
+				p := tt.project
+				if p.Template == "" {
+					p.Template = "basic"
+				}
+				err := s.Create(&p)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("Create error = %v, want it to contain %q", err, tt.wantErr)
+					}
+					if _, err := s.Get(p.Name); err == nil {
+						t.Errorf("Get(%s) succeeded after a failed Create", p.Name)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+				got, err := s.Get(p.Name)
+				if err != nil {
+					t.Fatalf("Get failed: %v", err)
+				}
+				if tt.check != nil {
+					tt.check(t, got)
+				}
+			})
+		})
+	}
+}
+
+func TestStoreCreateExisting(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		mustCreate(t, s, &model.Project{Name: "api"})
+		err := s.Create(&model.Project{Name: "api", Template: "basic"})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Errorf("Create error = %v, want already exists", err)
+		}
+	})
+}
+
+func TestStoreGetMissing(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		if _, err := s.Get("nope"); err == nil {
+			t.Error("Get succeeded for a missing project")
+		}
+	})
+}
+
+func TestStoreUpdate(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		p := mustCreate(t, s, &model.Project{Name: "api"})
+		stale, _ := s.Get("api")
+
+		p.Pinned = true
+		p.Tags = []string{"NEW"}
+		if err := s.Update(p); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		got, err := s.Get("api")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if !got.Pinned || !slices.Equal(got.Tags, []string{"new"}) || got.Revision != stale.Revision+1 {
+			t.Errorf("after Update got pinned %v tags %q revision %d", got.Pinned, got.Tags, got.Revision)
+		}
+
+		stale.Properties = map[string]string{"k": "v"}
+		if err := s.Update(stale); !errors.Is(err, ErrConflict) {
+			t.Errorf("Update of stale metadata = %v, want ErrConflict", err)
+		}
+		if got, _ := s.Get("api"); got.Properties != nil {
+			t.Errorf("stale Update was saved: %v", got.Properties)
+		}
+	})
+}
+
+func TestStoreList(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		for _, name := range []string{"zeta", "alpha", "mid"} {
+			mustCreate(t, s, &model.Project{Name: name})
+		}
+		mustCreate(t, s, &model.Project{Name: "scratch", Scratch: true})
+
+		projects, err := s.List()
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got := names(projects); !slices.Equal(got, []string{"alpha", "mid", "zeta"}) {
+			t.Errorf("List = %q, want [alpha mid zeta]", got)
+		}
+		if _, err := s.Get("scratch"); err != nil {
+			t.Errorf("Get(scratch) failed: %v", err)
+		}
+	})
+}
+
+func TestStoreRemoveAndRestore(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		mustCreate(t, s, &model.Project{Name: "api", Tags: []string{"web"}})
+		if err := s.Remove("api", "test: done"); err != nil {
+			t.Fatalf("Remove failed: %v", err)
+		}
+		if _, err := s.Get("api"); err == nil {
+			t.Error("Get succeeded after Remove")
+		}
+		if err := s.Remove("api", "again"); err == nil {
+			t.Error("Remove of a missing project succeeded")
+		}
+
+		trash, err := s.ListTrash()
+		if err != nil {
+			t.Fatalf("ListTrash failed: %v", err)
+		}
+		if len(trash) != 1 || trash[0].Project.Name != "api" || trash[0].Reason != "test: done" {
+			t.Fatalf("ListTrash = %+v, want api removed for test: done", trash)
+		}
+
+		// A new project with the same name blocks the restore
+		mustCreate(t, s, &model.Project{Name: "api"})
+		if _, err := s.RestoreTrash("api"); err == nil {
+			t.Error("RestoreTrash over an existing project succeeded")
+		}
+		if err := s.Remove("api", "second"); err != nil {
+			t.Fatalf("Remove failed: %v", err)
+		}
+
+		// The most recent removal comes back first
+		p, err := s.RestoreTrash("api")
+		if err != nil {
+			t.Fatalf("RestoreTrash failed: %v", err)
+		}
+		if len(p.Tags) != 0 {
+			t.Errorf("restored Tags = %q, want the second project's", p.Tags)
+		}
+		p, err = s.RestoreTrash("api")
+		if err == nil {
+			t.Errorf("RestoreTrash restored %s over the first restore", p.Name)
+		}
+
+		emptied, err := s.EmptyTrash(0)
+		if err != nil {
+			t.Fatalf("EmptyTrash failed: %v", err)
+		}
+		if len(emptied) != 1 {
+			t.Errorf("EmptyTrash removed %d entries, want 1", len(emptied))
+		}
+		if trash, _ := s.ListTrash(); len(trash) != 0 {
+			t.Errorf("ListTrash after EmptyTrash = %d entries, want none", len(trash))
+		}
+	})
+}
+
+func TestStoreRename(t *testing.T) {
+	tests := []struct {
+		name       string
+		module     string
+		to         string
+		wantModule string
+		wantErr    string
+	}{
+		{name: "generated module", to: "web", wantModule: "web"},
+		{name: "prefixed module", module: "github.com/me/api", to: "web", wantModule: "github.com/me/web"},
+		{name: "custom module", module: "example.com/custom", to: "web", wantModule: "example.com/custom"},
+		{name: "existing name", to: "other", wantErr: "already exists"},
+		{name: "invalid name", to: "Web", wantErr: "invalid project name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runContract(t, func(t *testing.T, s contractStore) {
+				mustCreate(t, s, &model.Project{Name: "api", Module: tt.module})
+				mustCreate(t, s, &model.Project{Name: "other"})
+				// Note bodies live in a file that is not Go source
+				if _, err := s.AddNote("api", "idea", "a body"); err != nil {
+					t.Fatalf("AddNote failed: %v", err)
+				}
+
+				p, err := s.Rename("api", tt.to)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Fatalf("Rename error = %v, want it to contain %q", err, tt.wantErr)
+					}
+					if _, err := s.Get("api"); err != nil {
+						t.Errorf("Get(api) after a failed Rename: %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Rename failed: %v", err)
+				}
+				if p.Name != tt.to || p.Module != tt.wantModule {
+					t.Errorf("Rename = %s with module %q, want %s with %q", p.Name, p.Module, tt.to, tt.wantModule)
+				}
+				if _, err := s.Get("api"); err == nil {
+					t.Error("Get(api) succeeded after Rename")
+				}
+
+				dir := t.TempDir()
+				if err := s.CopyTo(p, dir); err != nil {
+					t.Fatalf("CopyTo failed: %v", err)
+				}
+				goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+				if err != nil {
+					t.Fatalf("failed to read go.mod: %v", err)
+				}
+				if !strings.HasPrefix(string(goMod), "module "+tt.wantModule+"\n") {
+					t.Errorf("go.mod = %q, want module %s", goMod, tt.wantModule)
+				}
+			})
+		})
+	}
+}
+
+func TestStoreRenameUpdatesForks(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		mustCreate(t, s, &model.Project{Name: "api"})
+		if _, err := s.Fork("api", "api-v2", false); err != nil {
+			t.Fatalf("Fork failed: %v", err)
+		}
+		if _, err := s.Rename("api", "web"); err != nil {
+			t.Fatalf("Rename failed: %v", err)
+		}
+		fork, err := s.Get("api-v2")
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if fork.ForkedFrom != "web" {
+			t.Errorf("ForkedFrom = %q, want web", fork.ForkedFrom)
+		}
+	})
+}
+
+func TestStoreFork(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepNotes bool
+	}{
+		{"without notes", false},
+		{"with notes", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runContract(t, func(t *testing.T, s contractStore) {
+				mustCreate(t, s, &model.Project{Name: "api", Tags: []string{"web"}, Pinned: true})
+				if _, err := s.AddNote("api", "idea", "a body"); err != nil {
+					t.Fatalf("AddNote failed: %v", err)
+				}
+
+				fork, err := s.Fork("api", "api-v2", tt.keepNotes)
+				if err != nil {
+					t.Fatalf("Fork failed: %v", err)
+				}
+				if fork.ForkedFrom != "api" || fork.Module != "api-v2" || !slices.Equal(fork.Tags, []string{"web"}) {
+					t.Errorf("Fork = from %q module %q tags %q", fork.ForkedFrom, fork.Module, fork.Tags)
+				}
+				if fork.Pinned {
+					t.Error("Fork kept the pin")
+				}
+
+				bodies, err := s.NoteBodies("api-v2")
+				if err != nil {
+					t.Fatalf("NoteBodies failed: %v", err)
+				}
+				if got := len(fork.Notes) == 1 && bodies[1] == "a body"; got != tt.keepNotes {
+					t.Errorf("Fork notes %+v bodies %q, want kept: %v", fork.Notes, bodies, tt.keepNotes)
+				}
+
+				if _, err := s.Fork("api", "api-v2", false); err == nil {
+					t.Error("Fork onto an existing project succeeded")
+				}
+				if _, err := s.Fork("nope", "api-v3", false); err == nil {
+					t.Error("Fork of a missing project succeeded")
+				}
+			})
+		})
+	}
+}
+
+func TestStoreCopyTo(t *testing.T) {
+	runContract(t, func(t *testing.T, s contractStore) {
+		p := mustCreate(t, s, &model.Project{Name: "api"})
+		if _, err := s.AddNote("api", "idea", "a body"); err != nil {
+			t.Fatalf("AddNote failed: %v", err)
+		}
+
+		dir := t.TempDir()
+		if err := s.CopyTo(p, dir); err != nil {
+			t.Fatalf("CopyTo failed: %v", err)
+		}
+		for _, name := range []string{"go.mod", "main.go"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("CopyTo left out %s: %v", name, err)
+			}
+		}
+		for _, name := range []string{".goshed.json", notesFile} {
+			if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+				t.Errorf("CopyTo copied %s", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package project
+
+import (
+	"strings"
+)
+
+// NormalizeTags trims and lowercases tags, dropping empty ones and
+// duplicates while keeping the first occurrence's order. It never returns
+// nil, so metadata always records a list.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// ParseTags splits comma-separated lists of tags and normalizes them
+func ParseTags(lists ...string) []string {
+	var tags []string
+	for _, list := range lists {
+		tags = append(tags, strings.Split(list, ",")...)
+	}
+	return NormalizeTags(tags)
+}
@@ -0,0 +1,259 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+const (
+	trashDir       = "trash"
+	trashEntryFile = "entry.json"
+	trashFilesDir  = "files"
+)
+
+// Trasher is implemented by stores whose Remove keeps projects in a trash
+// they can be restored from
+type Trasher interface {
+	// ListTrash returns the projects in the trash, most recently removed
+	// first
+	ListTrash() ([]*TrashEntry, error)
+	// RestoreTrash brings the most recently removed project with the given
+	// name back from the trash
+	RestoreTrash(name string) (*model.Project, error)
+	// EmptyTrash permanently deletes the projects that have been in the
+	// trash for longer than olderThan and returns them
+	EmptyTrash(olderThan time.Duration) ([]*TrashEntry, error)
+}
+
+// TrashEntry describes a removed project waiting in the trash
+type TrashEntry struct {
+	ID        string         `json:"id"`
+	Project   *model.Project `json:"project"`
+	RemovedAt time.Time      `json:"removedAt"`
+	Reason    string         `json:"reason"`
+}
+
+// UnmarshalJSON decodes a trash entry, upgrading the project metadata in
+// it to the current schema
+func (e *TrashEntry) UnmarshalJSON(data []byte) error {
+	type plain TrashEntry
+	var raw struct {
+		plain
+		Project json.RawMessage `json:"project"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p, err := decodeEmbedded(raw.Project)
+	if err != nil {
+		return err
+	}
+	*e = TrashEntry(raw.plain)
+	e.Project = p
+	return nil
+}
+
+func (s *FSStore) trashPath(id string) string {
+	return filepath.Join(s.root, stateDir, trashDir, id)
+}
+
+// Remove moves a project into the workspace trash, recording when and why
+// it was removed. For a project registered by reference only the
+// registration is trashed; its files are never read or deleted, so a
+// registration whose directory is gone can still be removed.
+func (s *FSStore) Remove(name, reason string) error {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := s.Get(name)
+	if err != nil {
+		var ok bool
+		if p, ok = s.danglingRef(name); !ok {
+			return err
+		}
+	}
+
+	now := time.Now()
+	entry := &TrashEntry{
+		ID:        fmt.Sprintf("%s-%d", name, now.UnixNano()),
+		Project:   p,
+		RemovedAt: now,
+		Reason:    reason,
+	}
+	entryDir := s.trashPath(entry.ID)
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		return fmt.Errorf("failed to create trash entry: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		os.RemoveAll(entryDir)
+		return fmt.Errorf("failed to marshal trash entry: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(entryDir, trashEntryFile), data, 0644); err != nil {
+		os.RemoveAll(entryDir)
+		return fmt.Errorf("failed to write trash entry: %w", err)
+	}
+	// A project registered by reference only has its registration moved;
+	// its external directory is left untouched
+	if err := os.Rename(s.projectDir(name), filepath.Join(entryDir, trashFilesDir)); err != nil {
+		os.RemoveAll(entryDir)
+		return fmt.Errorf("failed to move project to trash: %w", err)
+	}
+
+	s.updateIndex(func(idx *index) error {
+		delete(idx.Entries, name)
+		return nil
+	})
+	return nil
+}
+
+// ListTrash returns the projects in the trash, most recently removed first
+func (s *FSStore) ListTrash() ([]*TrashEntry, error) {
+	dir := filepath.Join(s.root, stateDir, trashDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var trash []*TrashEntry
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, e.Name(), trashEntryFile))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read trash entry %s: %v\n", e.Name(), err)
+			continue
+		}
+		var entry TrashEntry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Project == nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read trash entry %s\n", e.Name())
+			continue
+		}
+		entry.ID = e.Name()
+		trash = append(trash, &entry)
+	}
+
+	sort.Slice(trash, func(i, j int) bool {
+		return trash[i].RemovedAt.After(trash[j].RemovedAt)
+	})
+	return trash, nil
+}
+
+// danglingRef returns the project registered by reference as name when its
+// directory no longer exists, built from the registration alone
+func (s *FSStore) danglingRef(name string) (*model.Project, bool) {
+	dir, external, err := s.resolveDir(name)
+	if err != nil || !external {
+		return nil, false
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return nil, false
+	}
+	return &model.Project{Name: name, Path: dir, External: true, Tags: []string{}}, true
+}
+
+// RestoreTrash moves the most recently removed project with the given name
+// out of the trash and back into the workspace
+func (s *FSStore) RestoreTrash(name string) (*model.Project, error) {
+	unlock, err := s.lockProject(name)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entry, err := findTrashEntry(s, name)
+	if err != nil {
+		return nil, err
+	}
+
+	projectDir := s.projectDir(name)
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		return nil, fmt.Errorf("project %s already exists", name)
+	}
+
+	entryDir := s.trashPath(entry.ID)
+	if data, err := os.ReadFile(filepath.Join(entryDir, trashFilesDir, refFile)); err == nil {
+		// Restoring a registration whose directory is gone would only
+		// bring back a broken project
+		var ref projectRef
+		if err := json.Unmarshal(data, &ref); err == nil {
+			if _, err := os.Stat(ref.Path); os.IsNotExist(err) {
+				return nil, fmt.Errorf("project %s refers to %s, which no longer exists", name, ref.Path)
+			}
+		}
+	}
+	if err := os.Rename(filepath.Join(entryDir, trashFilesDir), projectDir); err != nil {
+		return nil, fmt.Errorf("failed to restore project from trash: %w", err)
+	}
+	os.RemoveAll(entryDir)
+
+	p, err := s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.Scratch {
+		// A discarded scratch project that is restored was wanted after all
+		p.Scratch = false
+		if err := s.Update(p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	s.updateIndex(func(idx *index) error {
+		return s.indexProject(idx, p)
+	})
+	return p, nil
+}
+
+// EmptyTrash permanently deletes the projects that were removed more than
+// olderThan ago, returning the entries it deleted
+func (s *FSStore) EmptyTrash(olderThan time.Duration) ([]*TrashEntry, error) {
+	trash, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var emptied []*TrashEntry
+	for _, entry := range trash {
+		if entry.RemovedAt.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(s.trashPath(entry.ID)); err != nil {
+			return emptied, fmt.Errorf("failed to delete %s from trash: %w", entry.ID, err)
+		}
+		emptied = append(emptied, entry)
+	}
+	return emptied, nil
+}
+
+// findTrashEntry returns the most recently removed trash entry for a
+// project name
+func findTrashEntry(s Trasher, name string) (*TrashEntry, error) {
+	trash, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range trash {
+		if entry.Project.Name == name {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("project %s is not in the trash", name)
+}
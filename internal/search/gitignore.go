@@ -0,0 +1,128 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is one pattern from a .gitignore file
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// anchored rules match the path relative to the .gitignore; others
+	// match the base name at any depth
+	anchored bool
+}
+
+// ignoreRules holds the rules of every .gitignore found so far in a
+// project, keyed by the directory containing it ("" for the project root)
+type ignoreRules map[string][]ignoreRule
+
+// parseGitignore parses the contents of a .gitignore file
+func parseGitignore(data []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// globToRegexp translates a gitignore glob into a regular expression.
+// "**" crosses directories; "*" and "?" do not.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**"):
+			b.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignored reports whether the slash-separated path rel, relative to the
+// project root, is ignored by the rules of its ancestor directories. Rules
+// from deeper .gitignore files, and later rules within a file, win.
+func (r ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+
+	parts := strings.Split(rel, "/")
+	base := ""
+	for i := range parts {
+		// The rules of base apply to the path below it
+		sub := strings.Join(parts[i:], "/")
+		for _, rule := range r[base] {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			target := parts[len(parts)-1]
+			if rule.anchored {
+				target = sub
+			}
+			if rule.re.MatchString(target) {
+				ignored = !rule.negate
+			}
+		}
+		base = path.Join(base, parts[i])
+	}
+	return ignored
+}
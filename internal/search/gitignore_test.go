@@ -0,0 +1,83 @@
+package search
+
+import "testing"
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules ignoreRules
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"no rules", nil, "main.go", false, false},
+		{"base name at any depth", ignoreRules{"": parseGitignore([]byte("*.log\n"))}, "a/b/debug.log", false, true},
+		{"star stays within a segment", ignoreRules{"": parseGitignore([]byte("a*.go\n"))}, "ab/c.go", false, false},
+		{"question mark", ignoreRules{"": parseGitignore([]byte("file?.txt\n"))}, "file1.txt", false, true},
+		{"character class", ignoreRules{"": parseGitignore([]byte("[ab].txt\n"))}, "c.txt", false, false},
+		{"negated class", ignoreRules{"": parseGitignore([]byte("[!ab].txt\n"))}, "c.txt", false, true},
+		{"comments and blank lines", ignoreRules{"": parseGitignore([]byte("# main.go\n\n"))}, "main.go", false, false},
+		{"escaped hash", ignoreRules{"": parseGitignore([]byte(`\#notes` + "\n"))}, "#notes", false, true},
+		{"trailing spaces", ignoreRules{"": parseGitignore([]byte("bin \t\r\n"))}, "bin", false, true},
+		{"directory rule matches a directory", ignoreRules{"": parseGitignore([]byte("build/\n"))}, "build", true, true},
+		{"directory rule skips files", ignoreRules{"": parseGitignore([]byte("build/\n"))}, "build", false, false},
+		{"anchored to the root", ignoreRules{"": parseGitignore([]byte("/bin\n"))}, "bin", true, true},
+		{"anchored rule below the root", ignoreRules{"": parseGitignore([]byte("/bin\n"))}, "cmd/bin", true, false},
+		{"slash in the middle anchors", ignoreRules{"": parseGitignore([]byte("docs/*.md\n"))}, "x/docs/a.md", false, false},
+		{"double star prefix", ignoreRules{"": parseGitignore([]byte("**/gen\n"))}, "a/b/gen", true, true},
+		{"double star suffix", ignoreRules{"": parseGitignore([]byte("out/**\n"))}, "out/a/b.txt", false, true},
+		{"double star middle", ignoreRules{"": parseGitignore([]byte("a/**/z\n"))}, "a/z", false, true},
+		{"negation", ignoreRules{"": parseGitignore([]byte("*.log\n!keep.log\n"))}, "keep.log", false, false},
+		{"later rule wins", ignoreRules{"": parseGitignore([]byte("!keep.log\n*.log\n"))}, "keep.log", false, true},
+		{
+			"nested .gitignore applies below its directory",
+			ignoreRules{"sub": parseGitignore([]byte("/data\n"))},
+			"sub/data", true, true,
+		},
+		{
+			"nested .gitignore does not apply elsewhere",
+			ignoreRules{"sub": parseGitignore([]byte("data\n"))},
+			"data", true, false,
+		},
+		{
+			"deeper .gitignore wins",
+			ignoreRules{"": parseGitignore([]byte("*.txt\n")), "sub": parseGitignore([]byte("!keep.txt\n"))},
+			"sub/keep.txt", false, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.ignored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGitignore(t *testing.T) {
+	tests := []struct {
+		data string
+		want []ignoreRule
+	}{
+		{"", nil},
+		{"# only a comment\n\n", nil},
+		{"/\n!\n", nil},
+		{"*.log", []ignoreRule{{}}},
+		{"!/build/", []ignoreRule{{negate: true, dirOnly: true, anchored: true}}},
+		{"a/b", []ignoreRule{{anchored: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got := parseGitignore([]byte(tt.data))
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseGitignore(%q) returned %d rules, want %d", tt.data, len(got), len(tt.want))
+			}
+			for i, rule := range got {
+				want := tt.want[i]
+				if rule.negate != want.negate || rule.dirOnly != want.dirOnly || rule.anchored != want.anchored {
+					t.Errorf("rule %d = %+v, want %+v", i, rule, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Package search finds text and Go symbols across the playgrounds of a
+// workspace
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/crazywolf132/goshed/internal/model"
+)
+
+// maxFileSize is the largest file searched; bigger files are skipped
+const maxFileSize = 1 << 20
+
+// Kinds of match
+const (
+	KindCode   = "code"
+	KindNote   = "note"
+	KindTag    = "tag"
+	KindName   = "name"
+	KindSymbol = "symbol"
+)
+
+// Matcher finds the byte ranges of matches in a line, as returned by
+// regexp.Regexp.FindAllStringIndex
+type Matcher interface {
+	FindAllStringIndex(s string, n int) [][]int
+}
+
+// Options controls a search
+type Options struct {
+	// Symbols restricts the search to the names of Go functions, methods
+	// and types
+	Symbols bool
+	// Workers is the number of files searched concurrently. Zero means one
+	// per CPU.
+	Workers int
+}
+
+// Match is one search hit
+type Match struct {
+	Project string
+	Kind    string
+	// File is relative to the project, or empty for a metadata match
+	File string
+	// Line is 1-based, or 0 for a metadata match
+	Line int
+	Text string
+	// Spans are the byte ranges of the match within Text
+	Spans [][]int
+}
+
+// noteMarker starts a note body in NOTES.md. The marker and the heading
+// after it are generated from the note text, which searchMetadata already
+// matches.
+var noteMarker = regexp.MustCompile(`^<!-- note \d+ -->$`)
+
+// job is one file to search
+type job struct {
+	project string
+	root    string
+	rel     string
+}
+
+// Search looks for m in the names, tags and notes of the projects and in
+// their Go source and NOTES.md files. Files ignored by .gitignore are
+// skipped. With opts.Symbols set only Go declarations are searched.
+// Matches are sorted by project, file and line.
+func Search(projects []*model.Project, m Matcher, opts Options) ([]Match, error) {
+	var matches []Match
+	if !opts.Symbols {
+		for _, p := range projects {
+			matches = append(matches, searchMetadata(p, m)...)
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan job)
+	results := make(chan []Match)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var found []Match
+				if opts.Symbols {
+					found = searchSymbols(j, m)
+				} else {
+					found = searchFile(j, m)
+				}
+				if len(found) > 0 {
+					results <- found
+				}
+			}
+		}()
+	}
+
+	walkErr := make(chan error, 1)
+	go func() {
+		var errs []error
+		for _, p := range projects {
+			if p.Path == "" {
+				continue
+			}
+			if err := walk(p, opts.Symbols, jobs); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+		if len(errs) > 0 {
+			walkErr <- errs[0]
+		}
+		close(walkErr)
+	}()
+
+	for found := range results {
+		matches = append(matches, found...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return matches, <-walkErr
+}
+
+// walk sends every searchable file of a project to jobs, honouring the
+// project's .gitignore files
+func walk(p *model.Project, goOnly bool, jobs chan<- job) error {
+	rules := make(ignoreRules)
+	err := filepath.WalkDir(p.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(p.Path, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (d.Name() == ".git" || rules.ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			base := rel
+			if base == "." {
+				base = ""
+			}
+			if data, err := os.ReadFile(filepath.Join(path, ".gitignore")); err == nil {
+				rules[base] = parseGitignore(data)
+			}
+			return nil
+		}
+
+		if !searchable(d.Name(), goOnly) || rules.ignored(rel, false) {
+			return nil
+		}
+		jobs <- job{project: p.Name, root: p.Path, rel: rel}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// searchable reports whether a file is searched: Go source and, unless
+// goOnly is set, go.mod and the notes file
+func searchable(name string, goOnly bool) bool {
+	if strings.HasSuffix(name, ".go") {
+		return true
+	}
+	return !goOnly && (name == "go.mod" || name == "NOTES.md")
+}
+
+// searchMetadata matches a project's name, tags and note texts
+func searchMetadata(p *model.Project, m Matcher) []Match {
+	var matches []Match
+	add := func(kind, text string) {
+		if spans := m.FindAllStringIndex(text, -1); len(spans) > 0 {
+			matches = append(matches, Match{Project: p.Name, Kind: kind, Text: text, Spans: spans})
+		}
+	}
+
+	add(KindName, p.Name)
+	for _, tag := range p.Tags {
+		add(KindTag, tag)
+	}
+	for _, n := range p.Notes {
+		add(KindNote, fmt.Sprintf("#%d %s", n.ID, n.Text))
+	}
+	return matches
+}
+
+// readSearchable reads a file unless it is too large or looks binary
+func readSearchable(path string) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFileSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// searchFile matches each line of a file
+func searchFile(j job, m Matcher) []Match {
+	data, ok := readSearchable(filepath.Join(j.root, j.rel))
+	if !ok {
+		return nil
+	}
+
+	kind := KindCode
+	if filepath.Base(j.rel) == "NOTES.md" {
+		kind = KindNote
+	}
+
+	var matches []Match
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	heading := false
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if kind == KindNote {
+			// Skip the generated title, markers and headings
+			generated := line == 1 && text == "# Notes" || noteMarker.MatchString(text) ||
+				heading && strings.HasPrefix(text, "## ")
+			heading = noteMarker.MatchString(text)
+			if generated {
+				continue
+			}
+		}
+		if spans := m.FindAllStringIndex(text, -1); len(spans) > 0 {
+			matches = append(matches, Match{Project: j.project, Kind: kind, File: j.rel, Line: line, Text: text, Spans: spans})
+		}
+	}
+	return matches
+}
+
+// searchSymbols matches the names of the functions, methods and types
+// declared in a Go file. Methods are matched as Type.Method.
+func searchSymbols(j job, m Matcher) []Match {
+	data, ok := readSearchable(filepath.Join(j.root, j.rel))
+	if !ok {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, j.rel, data, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	var matches []Match
+	add := func(name string, pos token.Pos) {
+		spans := m.FindAllStringIndex(name, -1)
+		if len(spans) == 0 {
+			return
+		}
+		line := fset.Position(pos).Line
+		matches = append(matches, Match{
+			Project: j.project,
+			Kind:    KindSymbol,
+			File:    j.rel,
+			Line:    line,
+			Text:    name,
+			Spans:   spans,
+		})
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if recv := receiverType(d); recv != "" {
+				name = recv + "." + name
+			}
+			add(name, d.Name.Pos())
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					add(ts.Name.Name, ts.Name.Pos())
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// receiverType returns the type name of a method's receiver, or an empty
+// string for a function
+func receiverType(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return ""
+	}
+	expr := d.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
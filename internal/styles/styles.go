@@ -19,4 +19,8 @@ var (
 	FieldName   = color.New(color.FgBlue).SprintfFunc()
 	TagText     = color.New(color.FgHiMagenta).SprintfFunc()
 	TimeText    = color.New(color.FgHiGreen).SprintfFunc()
+
+	// Search results
+	Match = color.New(color.FgHiRed, color.Bold).SprintfFunc()
+	Path  = color.New(color.FgMagenta).SprintfFunc()
 )
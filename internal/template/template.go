@@ -1,7 +1,10 @@
 package template
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/crazywolf132/goshed/internal/model"
 )
@@ -178,16 +181,44 @@ type Resolver struct{}
 	},
 }
 
-// Get returns a template by name
+// ErrNotFound is returned by Get for a name no template has
+var ErrNotFound = errors.New("not found")
+
+// Get returns a template by name. A user template takes precedence over a
+// built-in one of the same name.
 func Get(name string) (*model.Template, error) {
+	user, broken := userTemplates()
+	if t, ok := user[name]; ok {
+		return t, nil
+	}
+	if err, ok := broken[name]; ok {
+		return nil, err
+	}
 	t, ok := templates[name]
 	if !ok {
-		return nil, fmt.Errorf("template %s not found", name)
+		return nil, fmt.Errorf("template %s %w", name, ErrNotFound)
 	}
 	return t, nil
 }
 
-// List returns all available templates
-func List() map[string]*model.Template {
-	return templates
+// List returns all available templates: the built-ins merged with the user
+// templates in UserDir, which replace built-ins of the same name. Templates
+// that fail to load are left out and reported in the returned error, along
+// with the ones that did load.
+func List() (map[string]*model.Template, error) {
+	user, broken := userTemplates()
+
+	merged := make(map[string]*model.Template, len(templates)+len(user))
+	for name, t := range templates {
+		merged[name] = t
+	}
+	for name, t := range user {
+		merged[name] = t
+	}
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(broken)) {
+		errs = append(errs, broken[name])
+	}
+	return merged, errors.Join(errs...)
 }
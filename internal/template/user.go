@@ -0,0 +1,151 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/crazywolf132/goshed/internal/config"
+	"github.com/crazywolf132/goshed/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFile describes a user template and is not copied into projects
+const manifestFile = "template.yaml"
+
+// manifest is the contents of a user template's template.yaml
+type manifest struct {
+	Description  string   `yaml:"description"`
+	Dependencies []string `yaml:"dependencies"`
+}
+
+// UserDir returns the directory user templates are loaded from,
+// ~/.goshed/templates, or an empty string before the config is loaded
+func UserDir() string {
+	if config.ConfigDir == "" {
+		return ""
+	}
+	return filepath.Join(config.ConfigDir, "templates")
+}
+
+var (
+	// userCache holds the user templates loaded from userCacheDir, so that
+	// their files are only read once per run
+	userCacheMu  sync.Mutex
+	userCacheDir string
+	userCache    map[string]*model.Template
+	userBroken   map[string]error
+)
+
+// userTemplates returns the user templates in UserDir, loading them on
+// first use, and the errors of those that failed to load
+func userTemplates() (map[string]*model.Template, map[string]error) {
+	userCacheMu.Lock()
+	defer userCacheMu.Unlock()
+
+	dir := UserDir()
+	if userCache == nil || dir != userCacheDir {
+		userCache, userBroken = loadUserTemplates(dir)
+		userCacheDir = dir
+	}
+	return userCache, userBroken
+}
+
+// loadUserTemplates reads every template directory in dir. Templates that
+// cannot be loaded are returned as errors by name instead.
+func loadUserTemplates(dir string) (map[string]*model.Template, map[string]error) {
+	loaded := make(map[string]*model.Template)
+	broken := make(map[string]error)
+	if dir == "" {
+		return loaded, broken
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			broken[""] = fmt.Errorf("failed to read template directory: %w", err)
+		}
+		return loaded, broken
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		t, err := loadUserTemplate(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			broken[entry.Name()] = fmt.Errorf("template %s: %w", entry.Name(), err)
+			continue
+		}
+		loaded[t.Name] = t
+	}
+	return loaded, broken
+}
+
+// loadUserTemplate reads a template's manifest and file tree, recording
+// the permissions of files that are not 0644. go.mod is left out, since
+// goshed generates it for each project.
+func loadUserTemplate(dir string) (*model.Template, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", manifestFile, err)
+	}
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestFile, err)
+	}
+
+	t := &model.Template{
+		Name:         filepath.Base(dir),
+		Description:  m.Description,
+		Files:        make(map[string]string),
+		Dependencies: m.Dependencies,
+		Path:         dir,
+	}
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == manifestFile || rel == "go.mod" || rel == "go.sum" || !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		t.Files[rel] = string(content)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if perm := info.Mode().Perm(); perm != 0644 {
+			if t.Modes == nil {
+				t.Modes = make(map[string]os.FileMode)
+			}
+			t.Modes[rel] = perm
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template files: %w", err)
+	}
+	if len(t.Files) == 0 {
+		return nil, fmt.Errorf("template has no files")
+	}
+	return t, nil
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/crazywolf132/goshed/internal/filter"
+	"github.com/crazywolf132/goshed/internal/model"
+	"github.com/crazywolf132/goshed/internal/project"
+)
+
+// projectItem is a project shown in the browser list
+type projectItem struct {
+	project *model.Project
+	lineage []string
+}
+
+func (i projectItem) Title() string {
+	if i.project.Pinned {
+		return "📌 " + i.project.Name
+	}
+	return i.project.Name
+}
+
+func (i projectItem) Description() string {
+	desc := i.project.Template
+	if i.project.External {
+		desc += " • external"
+	}
+	if len(i.project.Tags) > 0 {
+		desc += " • " + strings.Join(i.project.Tags, ", ")
+	}
+	return desc
+}
+
+func (i projectItem) FilterValue() string {
+	return i.project.Name + " " + strings.Join(i.project.Tags, " ")
+}
+
+// Browser lists the projects in a store alongside the details of the
+// selected one
+type Browser struct {
+	store  project.Store
+	list   list.Model
+	width  int
+	height int
+}
+
+// NewBrowser returns a Browser over the projects in the given store
+func NewBrowser(store project.Store) Browser {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = selectedListItemStyle
+	delegate.Styles.SelectedDesc = selectedListItemStyle.Copy().Italic(true)
+
+	l := list.New(nil, delegate, 0, 0)
+	l.Title = "Playgrounds"
+	l.SetShowHelp(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = titleStyle.Copy().MarginLeft(2)
+
+	return Browser{store: store, list: l}
+}
+
+// Reload reads the projects from the store's index, pinned projects first
+func (b *Browser) Reload() error {
+	projects, err := b.store.List()
+	if err != nil {
+		return err
+	}
+
+	project.SortPinnedFirst(projects)
+	items := make([]list.Item, 0, len(projects))
+	for _, p := range projects {
+		items = append(items, projectItem{project: p, lineage: project.Lineage(p, projects)})
+	}
+	b.list.Filter = queryFilter(projects)
+	b.list.SetItems(items)
+	return nil
+}
+
+// queryFilter matches the filter box as a query, e.g. "tag:web and not
+// pinned", over projects in the same order as the list items. Plain words
+// and queries that don't parse yet, while still being typed, fall back to
+// fuzzy matching of names and tags. Activity and sizes are the cached
+// values rather than refreshed from disk.
+func queryFilter(projects []*model.Project) list.FilterFunc {
+	index := make(map[*model.Project]int, len(projects))
+	for i, p := range projects {
+		index[p] = i
+	}
+
+	return func(term string, targets []string) []list.Rank {
+		q, err := filter.Parse(term)
+		if err != nil || !q.Structured() {
+			return list.DefaultFilter(term, targets)
+		}
+
+		matched := q.Filter(projects, time.Now())
+		ranks := make([]list.Rank, len(matched))
+		for i, p := range matched {
+			ranks[i] = list.Rank{Index: index[p]}
+		}
+		return ranks
+	}
+}
+
+// Select moves the cursor to the named project
+func (b *Browser) Select(name string) {
+	for i, it := range b.list.Items() {
+		if it.(projectItem).project.Name == name {
+			b.list.Select(i)
+			return
+		}
+	}
+}
+
+// Selected returns the project under the cursor, if any
+func (b Browser) Selected() (*model.Project, bool) {
+	it, ok := b.list.SelectedItem().(projectItem)
+	if !ok {
+		return nil, false
+	}
+	return it.project, true
+}
+
+// Filtering reports whether the user is typing a filter, in which case key
+// presses belong to the filter box
+func (b Browser) Filtering() bool {
+	return b.list.FilterState() == list.Filtering
+}
+
+func (b *Browser) SetSize(width, height int) {
+	b.width = width
+	b.height = height
+	b.list.SetSize(width/2-8, height)
+}
+
+func (b Browser) Update(msg tea.Msg) (Browser, tea.Cmd) {
+	var cmd tea.Cmd
+	b.list, cmd = b.list.Update(msg)
+	return b, cmd
+}
+
+func (b Browser) View() string {
+	left := listStyle.Copy().Width(b.width/2 - 4).Render(b.list.View())
+
+	it, ok := b.list.SelectedItem().(projectItem)
+	if !ok {
+		return left
+	}
+	right := listStyle.Copy().Width(b.width/2 - 4).Render(detailView(it))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+}
+
+// maxDetailNotes is the number of notes shown in the detail view
+const maxDetailNotes = 5
+
+// detailView renders the metadata of a project
+func detailView(it projectItem) string {
+	p := it.project
+	field := func(name, value string) string {
+		return fmt.Sprintf("%s %s", selectedStyle.Render(name), value)
+	}
+
+	details := []string{
+		field("Name:", p.Name),
+		field("Template:", p.Template),
+	}
+	if p.Pinned {
+		details = append(details, field("Pinned:", "yes"))
+	}
+	if p.External {
+		details = append(details, field("Path:", p.Path+" (external)"))
+	}
+	details = append(details,
+		field("Created:", p.Created.Format(time.RFC3339)),
+		field("Accessed:", p.LastAccessed.Format(time.RFC3339)),
+	)
+	if p.ExpiresAt != nil {
+		details = append(details, field("Expires:", p.ExpiresAt.Format(time.RFC3339)))
+	}
+	if len(p.Tags) > 0 {
+		details = append(details, field("Tags:", strings.Join(p.Tags, ", ")))
+	}
+	if len(it.lineage) > 0 {
+		details = append(details, field("Forked from:", strings.Join(it.lineage, " ← ")))
+	}
+	if len(p.Properties) > 0 {
+		details = append(details, "", selectedStyle.Render("Properties:"))
+		keys := make([]string, 0, len(p.Properties))
+		for key := range p.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			details = append(details, fmt.Sprintf("  %s = %s", key, p.Properties[key]))
+		}
+	}
+	if len(p.Notes) > 0 {
+		details = append(details, "", selectedStyle.Render("Notes:"))
+		// Show the most recent entries, oldest first
+		notes := p.Notes
+		if len(notes) > maxDetailNotes {
+			notes = notes[len(notes)-maxDetailNotes:]
+		}
+		for _, n := range notes {
+			details = append(details, fmt.Sprintf("  #%d %s  %s", n.ID, n.Created.Format("2006-01-02"), n.Text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, details...)
+}
@@ -2,7 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -12,6 +16,14 @@ import (
 	"github.com/crazywolf132/goshed/internal/model"
 	"github.com/crazywolf132/goshed/internal/project"
 	"github.com/crazywolf132/goshed/internal/template"
+	"github.com/spf13/viper"
+)
+
+type screen int
+
+const (
+	screenProjects screen = iota
+	screenCreate
 )
 
 type state int
@@ -20,14 +32,23 @@ const (
 	stateProjectName state = iota
 	stateTemplate
 	stateTags
+	stateModule
+	stateGoVersion
+	stateToolchain
 	stateConfirm
 )
 
 type Model struct {
+	store       project.Store
+	screen      screen
+	browser     Browser
 	state       state
 	projectName textinput.Model
 	templates   list.Model
 	tags        textinput.Model
+	module      textinput.Model
+	goVersion   textinput.Model
+	toolchain   textinput.Model
 	err         error
 	quitting    bool
 	spinner     spinner.Model
@@ -37,6 +58,21 @@ type Model struct {
 	showHelp    bool
 }
 
+// projectCreatedMsg is sent once the creator has created a project
+type projectCreatedMsg struct {
+	name string
+}
+
+// editorFinishedMsg is sent when the editor opened from the browser exits
+type editorFinishedMsg struct {
+	err error
+}
+
+// errMsg reports a failed background operation
+type errMsg struct {
+	err error
+}
+
 type item struct {
 	name, desc string
 }
@@ -45,7 +81,9 @@ func (i item) Title() string       { return i.name }
 func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.name }
 
-func InitialModel() Model {
+// InitialModel returns the interactive UI bound to the given store. It
+// starts on the project browser.
+func InitialModel(store project.Store) Model {
 	// Project name input
 	pn := textinput.New()
 	pn.Placeholder = "Enter project name"
@@ -54,10 +92,11 @@ func InitialModel() Model {
 	pn.Width = 40
 
 	// Template selection
-	templates := template.List()
+	// Broken user templates are left out and reported below
+	templates, templateErr := template.List()
 	items := make([]list.Item, 0, len(templates))
-	for name, tmpl := range templates {
-		items = append(items, item{name: name, desc: tmpl.Description})
+	for _, name := range slices.Sorted(maps.Keys(templates)) {
+		items = append(items, item{name: name, desc: templates[name].Description})
 	}
 
 	delegate := list.NewDefaultDelegate()
@@ -76,16 +115,44 @@ func InitialModel() Model {
 	tags.CharLimit = 100
 	tags.Width = 40
 
+	// Module path, go version and toolchain inputs; empty means default
+	module := textinput.New()
+	module.CharLimit = 200
+	module.Width = 40
+
+	goVersion := textinput.New()
+	goVersion.Placeholder = project.DefaultGoVersion
+	goVersion.CharLimit = 20
+	goVersion.Width = 40
+
+	toolchain := textinput.New()
+	toolchain.Placeholder = "none (e.g. go1.23.4)"
+	toolchain.CharLimit = 30
+	toolchain.Width = 40
+
 	// Spinner for loading states
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#7571F9"))
 
+	browser := NewBrowser(store)
+	err := browser.Reload()
+	if err == nil {
+		err = templateErr
+	}
+
 	return Model{
+		store:       store,
+		screen:      screenProjects,
+		browser:     browser,
+		err:         err,
 		state:       stateProjectName,
 		projectName: pn,
 		templates:   templateList,
 		tags:        tags,
+		module:      module,
+		goVersion:   goVersion,
+		toolchain:   toolchain,
 		spinner:     s,
 		preview:     NewPreview(),
 		showHelp:    false,
@@ -97,39 +164,210 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case projectCreatedMsg:
+		m.err = m.browser.Reload()
+		m.browser.Select(msg.name)
+		m.screen = screenProjects
+		return m, nil
+	case editorFinishedMsg:
+		m.err = msg.err
+		if err := m.browser.Reload(); err != nil {
+			m.err = err
+		}
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.browser.SetSize(msg.Width, msg.Height-8)
+		if m.preview.visible {
+			m.templates.SetSize(msg.Width/2-4, msg.Height-8)
+			m.preview.SetSize(msg.Width/2-4, msg.Height-8)
+		} else {
+			m.templates.SetSize(msg.Width-4, msg.Height-8)
+		}
+		return m, nil
+	}
+
+	if m.screen == screenProjects {
+		return m.updateBrowser(msg)
+	}
+	return m.updateCreator(msg)
+}
+
+func (m Model) updateBrowser(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+		// While filtering, keys belong to the filter box
+		if !m.browser.Filtering() {
+			switch msg.String() {
+			case "q":
+				m.quitting = true
+				return m, tea.Quit
+			case "n":
+				m.startCreator()
+				return m, textinput.Blink
+			case "o", "enter":
+				if p, ok := m.browser.Selected(); ok {
+					return m, m.openProject(p)
+				}
+				return m, nil
+			case "?":
+				m.showHelp = !m.showHelp
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.browser, cmd = m.browser.Update(msg)
+	return m, cmd
+}
+
+// startCreator switches to a fresh project creator
+func (m *Model) startCreator() {
+	m.screen = screenCreate
+	m.state = stateProjectName
+	m.err = nil
+	m.projectName.SetValue("")
+	m.projectName.Placeholder = project.GenerateName(m.projectExists)
+	m.tags.SetValue("")
+	m.module.SetValue("")
+	m.goVersion.SetValue("")
+	m.toolchain.SetValue("")
+	m.focusInput()
+}
+
+// focusInput focuses the text input of the current creator step, if it
+// has one, and blurs the others
+func (m *Model) focusInput() {
+	inputs := map[state]*textinput.Model{
+		stateProjectName: &m.projectName,
+		stateTags:        &m.tags,
+		stateModule:      &m.module,
+		stateGoVersion:   &m.goVersion,
+		stateToolchain:   &m.toolchain,
+	}
+	for st, input := range inputs {
+		if st == m.state {
+			input.Focus()
+		} else {
+			input.Blur()
+		}
+	}
+}
+
+// modulePath returns the module path the creator will use
+func (m Model) modulePath() string {
+	if m.module.Value() != "" {
+		return m.module.Value()
+	}
+	return project.DefaultModulePath(viper.GetString("module_prefix"), m.projectName.Value())
+}
+
+// openProject opens a project in the configured editor, suspending the UI
+// until the editor exits
+func (m Model) openProject(p *model.Project) tea.Cmd {
+	p.LastAccessed = time.Now()
+	if err := m.store.Update(p); err != nil {
+		return func() tea.Msg { return errMsg{err} }
+	}
+
+	editor := viper.GetString("editor")
+	if editor == "" {
+		editor = "code" // Default to VS Code
+	}
+
+	c := exec.Command(editor, p.Path)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return editorFinishedMsg{err}
+	})
+}
+
+func (m Model) updateCreator(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
+	prev := m.state
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "y", "n":
+			if m.state == stateConfirm {
+				if msg.String() == "y" {
+					return m, m.createProject
+				}
+				m.screen = screenProjects
+				return m, nil
+			}
 		case "enter":
 			switch m.state {
 			case stateProjectName:
-				if m.projectName.Value() != "" {
-					m.state = stateTemplate
+				// An empty name takes the generated suggestion
+				if m.projectName.Value() == "" {
+					m.projectName.SetValue(m.projectName.Placeholder)
+				}
+				if err := project.ValidateName(m.projectName.Value()); err != nil {
+					m.err = err
+					return m, nil
+				}
+				if m.projectExists(m.projectName.Value()) {
+					m.err = fmt.Errorf("project %s already exists", m.projectName.Value())
+					return m, nil
 				}
+				m.err = nil
+				m.state = stateTemplate
 			case stateTemplate:
 				m.state = stateTags
-				m.tags.Focus()
 			case stateTags:
+				m.module.Placeholder = m.modulePath()
+				m.state = stateModule
+			case stateModule:
+				if err := project.ValidateModulePath(m.modulePath()); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.err = nil
+				m.state = stateGoVersion
+			case stateGoVersion:
+				if v := m.goVersion.Value(); v != "" {
+					if err := project.ValidateGoVersion(v); err != nil {
+						m.err = err
+						return m, nil
+					}
+				}
+				m.err = nil
+				m.state = stateToolchain
+			case stateToolchain:
+				if v := m.toolchain.Value(); v != "" {
+					if _, err := project.NormalizeToolchain(v); err != nil {
+						m.err = err
+						return m, nil
+					}
+				}
+				m.err = nil
 				m.state = stateConfirm
 			case stateConfirm:
-				if msg.String() == "y" {
-					return m, m.createProject
-				}
-				m.quitting = true
-				return m, tea.Quit
+				return m, m.createProject
 			}
 		case "esc":
+			if m.state == stateProjectName {
+				m.screen = screenProjects
+				m.err = nil
+				return m, nil
+			}
 			if m.state > stateProjectName {
 				m.state--
-				if m.state == stateProjectName {
-					m.projectName.Focus()
-					m.tags.Blur()
-				}
+				m.err = nil
 			}
 		case "tab":
 			if m.state == stateTemplate {
@@ -143,16 +381,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "?":
 			m.showHelp = !m.showHelp
 		}
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		if m.preview.visible {
-			m.templates.SetSize(msg.Width/2-4, msg.Height-8)
-			m.preview.SetSize(msg.Width/2-4, msg.Height-8)
-		} else {
-			m.templates.SetSize(msg.Width-4, msg.Height-8)
-		}
-		return m, nil
+	}
+
+	// Move the focus along with the step
+	if m.state != prev {
+		m.focusInput()
 	}
 
 	switch m.state {
@@ -165,6 +398,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateTags:
 		m.tags, cmd = m.tags.Update(msg)
 		return m, cmd
+	case stateModule:
+		m.module, cmd = m.module.Update(msg)
+		return m, cmd
+	case stateGoVersion:
+		m.goVersion, cmd = m.goVersion.Update(msg)
+		return m, cmd
+	case stateToolchain:
+		m.toolchain, cmd = m.toolchain.Update(msg)
+		return m, cmd
 	}
 
 	return m, nil
@@ -178,12 +420,18 @@ func (m Model) View() string {
 	var s strings.Builder
 
 	// Header
-	header := titleStyle.Render("🏗  GoShed Project Creator")
+	title := "🏗  GoShed Project Creator"
+	if m.screen == screenProjects {
+		title = "🏗  GoShed"
+	}
+	header := titleStyle.Render(title)
 	s.WriteString(lipgloss.Place(m.width, 3, lipgloss.Center, lipgloss.Center, header))
 	s.WriteString("\n\n")
 
 	// Main content with optional preview
-	if m.state == stateTemplate && m.preview.visible {
+	if m.screen == screenProjects {
+		s.WriteString(lipgloss.Place(m.width, m.height-6, lipgloss.Center, lipgloss.Center, m.browser.View()))
+	} else if m.state == stateTemplate && m.preview.visible {
 		left := m.templates.View()
 		right := m.preview.View()
 		content := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
@@ -200,7 +448,11 @@ func (m Model) View() string {
 	}
 
 	// Help text
-	help := helpStyle.Render(getHelp(m.state))
+	helpText := getHelp(m.state)
+	if m.screen == screenProjects {
+		helpText = "↑/↓ to select • / to filter • n for new project • o to open • q to quit"
+	}
+	help := helpStyle.Render(helpText)
 	s.WriteString("\n" + lipgloss.Place(m.width, 2, lipgloss.Center, lipgloss.Bottom, help))
 
 	return s.String()
@@ -227,6 +479,30 @@ func (m Model) getStateView() string {
 			),
 		)
 
+	case stateModule:
+		return inputStyle.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				"Module path:",
+				m.module.View(),
+			),
+		)
+
+	case stateGoVersion:
+		return inputStyle.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				"Go version:",
+				m.goVersion.View(),
+			),
+		)
+
+	case stateToolchain:
+		return inputStyle.Render(
+			lipgloss.JoinVertical(lipgloss.Left,
+				"Toolchain:",
+				m.toolchain.View(),
+			),
+		)
+
 	case stateConfirm:
 		return confirmationView(m)
 
@@ -248,6 +524,18 @@ func confirmationView(m Model) string {
 		details = append(details, fmt.Sprintf("%s %s", selectedStyle.Render("Tags:"), m.tags.Value()))
 	}
 
+	goVersion := m.goVersion.Value()
+	if goVersion == "" {
+		goVersion = project.DefaultGoVersion
+	}
+	details = append(details,
+		fmt.Sprintf("%s %s", selectedStyle.Render("Module:"), m.modulePath()),
+		fmt.Sprintf("%s %s", selectedStyle.Render("Go:"), goVersion),
+	)
+	if m.toolchain.Value() != "" {
+		details = append(details, fmt.Sprintf("%s %s", selectedStyle.Render("Toolchain:"), m.toolchain.Value()))
+	}
+
 	s.WriteString(lipgloss.JoinVertical(lipgloss.Left, details...))
 	s.WriteString("\n\nPress 'y' to create, 'n' to cancel")
 
@@ -264,11 +552,13 @@ func goodbyeView() string {
 func getHelp(s state) string {
 	switch s {
 	case stateProjectName:
-		return "Enter project name • Ctrl+c to quit"
+		return "Enter project name, or leave empty for the suggestion • Esc to cancel • Ctrl+c to quit"
 	case stateTemplate:
 		return "↑/↓ to select • Tab to preview • Enter to confirm • Esc to go back • ? for help • Ctrl+c to quit"
 	case stateTags:
 		return "Enter tags • Enter to confirm • Esc to go back • Ctrl+c to quit"
+	case stateModule, stateGoVersion, stateToolchain:
+		return "Leave empty for the default • Enter to confirm • Esc to go back • Ctrl+c to quit"
 	case stateConfirm:
 		return "y/n to confirm • Esc to go back • Ctrl+c to quit"
 	default:
@@ -276,18 +566,27 @@ func getHelp(s state) string {
 	}
 }
 
+// projectExists reports whether the store already has a project with the
+// given name
+func (m Model) projectExists(name string) bool {
+	return project.NameTaken(m.store, name)
+}
+
 func (m Model) createProject() tea.Msg {
 	p := &model.Project{
-		Name:     m.projectName.Value(),
-		Template: m.templates.SelectedItem().(item).name,
-		Tags:     strings.Split(m.tags.Value(), ","),
+		Name:         m.projectName.Value(),
+		Created:      time.Now(),
+		LastAccessed: time.Now(),
+		Template:     m.templates.SelectedItem().(item).name,
+		Module:       m.modulePath(),
+		GoVersion:    m.goVersion.Value(),
+		Toolchain:    m.toolchain.Value(),
+		Tags:         project.ParseTags(m.tags.Value()),
 	}
 
-	if err := project.Create(p); err != nil {
-		m.err = err
-		return nil
+	if err := m.store.Create(p); err != nil {
+		return errMsg{err}
 	}
 
-	m.quitting = true
-	return tea.Quit()
+	return projectCreatedMsg{name: p.Name}
 }